@@ -21,12 +21,43 @@ type CarbonAwareKarmadaPolicySpec struct {
 	// reference to the karmada object to scale
 	// +kubebuilder:validation:Required
 	KarmadaTargetRef KarmadaTargetRef `json:"karmadaTargetRef"`
+
+	// how often the policy is evaluated. By default it is evaluated when
+	// the carbon intensity data for its clusters expires.
+	// +optional
+	EvaluationInterval *metav1.Duration `json:"evaluationInterval,omitempty"`
+
+	// reference to the karmada control plane of the karmada object. By
+	// default the karmada API server of the operator is used.
+	// +optional
+	ControlPlaneRef *ControlPlaneRef `json:"controlPlaneRef,omitempty"`
+
+	// how the selected clusters are written to the karmada object.
+	// ClusterAffinity sets the desired clusters as the cluster names of
+	// the cluster affinity. ClusterAffinities sets ordered cluster
+	// affinity groups of desiredClusters clusters each ranked by carbon
+	// intensity so karmada fails over to the next greenest clusters.
+	// +kubebuilder:default=ClusterAffinity
+	// +optional
+	PlacementMode PlacementMode `json:"placementMode,omitempty"`
 }
 
 // CarbonAwareKarmadaPolicyStatus defines the observed state of CarbonAwareKarmadaPolicy
 type CarbonAwareKarmadaPolicyStatus struct {
 	ActiveClusters []string        `json:"activeClusters"`
 	Clusters       []ClusterStatus `json:"clusters"`
+
+	// tiers of clusters last set in the placement of the karmada object.
+	// The first tier is the active clusters. Changes to any tier by
+	// someone else are reported as drift.
+	// +optional
+	ClusterTiers [][]string `json:"clusterTiers,omitempty"`
+
+	// conditions of the policy
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -74,8 +105,50 @@ type ClusterStatus struct {
 	IsValid         bool                         `json:"isValid"`
 	Location        string                       `json:"location"`
 	Name            string                       `json:"name"`
+
+	// carbon intensity is the last known good value because the
+	// provider is unavailable
+	// +optional
+	IsStale bool `json:"isStale,omitempty"`
+
+	// reason the carbon intensity is not valid
+	// +optional
+	Reason ClusterStatusReason `json:"reason,omitempty"`
+
+	// human readable message with details of the reason
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+// ClusterStatusReason represents why the carbon intensity for a cluster
+// is not valid.
+type ClusterStatusReason string
+
+const (
+	NoDataReason                 ClusterStatusReason = "NoData"
+	ProviderAuthFailedReason     ClusterStatusReason = "ProviderAuthFailed"
+	ProviderErrorReason          ClusterStatusReason = "ProviderError"
+	ProviderRateLimitedReason    ClusterStatusReason = "ProviderRateLimited"
+	ProviderTransportErrorReason ClusterStatusReason = "ProviderTransportError"
+	ProviderUnavailableReason    ClusterStatusReason = "ProviderUnavailable"
+	UnknownLocationReason        ClusterStatusReason = "UnknownLocation"
+)
+
+// TargetAcceptedCondition is true if the karmada target of the policy can
+// be updated by the operator.
+const TargetAcceptedCondition = "TargetAccepted"
+
+const (
+	AcceptedReason                      = "Accepted"
+	ClusterScopedTargetNotAllowedReason = "ClusterScopedTargetNotAllowed"
+	NamespaceNotWatchedReason           = "NamespaceNotWatched"
+	ReferenceNotPermittedReason         = "ReferenceNotPermitted"
+)
+
+// ReevaluateAnnotation is set on a policy to evaluate it again straight
+// away. The value is not used but setting a new value updates the policy.
+const ReevaluateAnnotation = "carbonaware.rossf7.github.io/reevaluate"
+
 // KarmadaTarget represents the type of the Karmada policy
 // Only one of the following Karmada policies is supported:
 // - clusterpropagationpolicies.policy.karmada.io
@@ -99,6 +172,24 @@ type KarmadaTargetRef struct {
 	Namespace string `json:"namespace"`
 }
 
+// PlacementMode represents how the selected clusters are written to the
+// placement of the Karmada policy
+// +kubebuilder:validation:Enum=ClusterAffinity;ClusterAffinities
+type PlacementMode string
+
+const (
+	ClusterAffinityPlacementMode   PlacementMode = "ClusterAffinity"
+	ClusterAffinitiesPlacementMode PlacementMode = "ClusterAffinities"
+)
+
+// ControlPlaneRef represents a KarmadaControlPlane in the namespace of
+// the policy
+type ControlPlaneRef struct {
+	// name of the karmada control plane
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
 func init() {
 	SchemeBuilder.Register(&CarbonAwareKarmadaPolicy{}, &CarbonAwareKarmadaPolicyList{})
 }
@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var carbonawarekarmadapolicylog = logf.Log.WithName("carbonawarekarmadapolicy-resource")
+
+// TargetPermitted returns whether the policy may update its Karmada target.
+// Propagation policies in another namespace may only be targeted if a
+// KarmadaTargetGrant in their namespace allows the namespace of the policy.
+// The grants are read with the reader which is the cluster the operator
+// runs in.
+func TargetPermitted(ctx context.Context, reader client.Reader, policy *CarbonAwareKarmadaPolicy) (bool, error) {
+	if policy.Spec.KarmadaTarget != PropagationPolicy || policy.Spec.KarmadaTargetRef.Namespace == policy.Namespace {
+		return true, nil
+	}
+
+	grants := &KarmadaTargetGrantList{}
+	err := reader.List(ctx, grants, client.InNamespace(policy.Spec.KarmadaTargetRef.Namespace))
+	if err != nil {
+		return false, fmt.Errorf("unable to list karmada target grants: %w", err)
+	}
+
+	for i := range grants.Items {
+		if grants.Items[i].Permits(policy.Namespace, policy.Spec.KarmadaTargetRef.Name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CarbonAwareKarmadaPolicyValidator validates that policies only target
+// propagation policies in other namespaces that they are granted.
+// +kubebuilder:object:generate=false
+type CarbonAwareKarmadaPolicyValidator struct {
+	Reader client.Reader
+}
+
+func (r *CarbonAwareKarmadaPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&CarbonAwareKarmadaPolicyValidator{Reader: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-carbonaware-rossf7-github-io-v1alpha1-carbonawarekarmadapolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=carbonaware.rossf7.github.io,resources=carbonawarekarmadapolicies,verbs=create;update,versions=v1alpha1,name=vcarbonawarekarmadapolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &CarbonAwareKarmadaPolicyValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *CarbonAwareKarmadaPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	policy, ok := obj.(*CarbonAwareKarmadaPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a CarbonAwareKarmadaPolicy but got %T", obj)
+	}
+	carbonawarekarmadapolicylog.Info("validate create", "name", policy.Name)
+
+	return nil, v.validateTarget(ctx, policy)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *CarbonAwareKarmadaPolicyValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	policy, ok := newObj.(*CarbonAwareKarmadaPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a CarbonAwareKarmadaPolicy but got %T", newObj)
+	}
+	carbonawarekarmadapolicylog.Info("validate update", "name", policy.Name)
+
+	return nil, v.validateTarget(ctx, policy)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *CarbonAwareKarmadaPolicyValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *CarbonAwareKarmadaPolicyValidator) validateTarget(ctx context.Context, policy *CarbonAwareKarmadaPolicy) error {
+	permitted, err := TargetPermitted(ctx, v.Reader, policy)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if permitted {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("CarbonAwareKarmadaPolicy").GroupKind(), policy.Name, field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "karmadaTargetRef", "namespace"),
+			fmt.Sprintf("no KarmadaTargetGrant in namespace %s allows policies in namespace %s to target %s",
+				policy.Spec.KarmadaTargetRef.Namespace, policy.Namespace, policy.Spec.KarmadaTargetRef.Name)),
+	})
+}
@@ -0,0 +1,101 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateTarget(t *testing.T) {
+	grant := &KarmadaTargetGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-team-a", Namespace: "team-b"},
+		Spec: KarmadaTargetGrantSpec{
+			From: []KarmadaTargetGrantFrom{{Namespace: "team-a"}},
+			To:   []KarmadaTargetGrantTo{{Name: "nginx-propagation"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		target    KarmadaTarget
+		targetRef KarmadaTargetRef
+		grants    []client.Object
+		allowed   bool
+	}{
+		{
+			name:      "same namespace",
+			namespace: "team-a",
+			target:    PropagationPolicy,
+			targetRef: KarmadaTargetRef{Name: "nginx-propagation", Namespace: "team-a"},
+			allowed:   true,
+		},
+		{
+			name:      "cross namespace without grant",
+			namespace: "team-a",
+			target:    PropagationPolicy,
+			targetRef: KarmadaTargetRef{Name: "nginx-propagation", Namespace: "team-b"},
+			allowed:   false,
+		},
+		{
+			name:      "cross namespace with grant",
+			namespace: "team-a",
+			target:    PropagationPolicy,
+			targetRef: KarmadaTargetRef{Name: "nginx-propagation", Namespace: "team-b"},
+			grants:    []client.Object{grant},
+			allowed:   true,
+		},
+		{
+			name:      "grant for another namespace",
+			namespace: "team-c",
+			target:    PropagationPolicy,
+			targetRef: KarmadaTargetRef{Name: "nginx-propagation", Namespace: "team-b"},
+			grants:    []client.Object{grant},
+			allowed:   false,
+		},
+		{
+			name:      "cluster propagation policy",
+			namespace: "team-a",
+			target:    ClusterPropagationPolicy,
+			targetRef: KarmadaTargetRef{Name: "nginx-propagation", Namespace: "team-b"},
+			allowed:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(AddToScheme(scheme)).To(Succeed())
+			validator := &CarbonAwareKarmadaPolicyValidator{
+				Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.grants...).Build(),
+			}
+
+			policy := &CarbonAwareKarmadaPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx-policy", Namespace: tc.namespace},
+				Spec: CarbonAwareKarmadaPolicySpec{
+					KarmadaTarget:    tc.target,
+					KarmadaTargetRef: tc.targetRef,
+				},
+			}
+
+			_, createErr := validator.ValidateCreate(context.Background(), policy)
+			_, updateErr := validator.ValidateUpdate(context.Background(), policy, policy)
+			if tc.allowed {
+				g.Expect(createErr).NotTo(HaveOccurred())
+				g.Expect(updateErr).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(createErr)).To(BeTrue())
+			g.Expect(createErr).To(MatchError(ContainSubstring("no KarmadaTargetGrant in namespace team-b")))
+			g.Expect(apierrors.IsInvalid(updateErr)).To(BeTrue())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultKubeconfigSecretKey is the key of the kubeconfig in the secret if
+// it is not set.
+const DefaultKubeconfigSecretKey = "kubeconfig"
+
+// KarmadaControlPlaneSpec defines the desired state of KarmadaControlPlane
+type KarmadaControlPlaneSpec struct {
+	// reference to the secret with a kubeconfig for the karmada API server
+	// +kubebuilder:validation:Required
+	KubeconfigSecretRef KubeconfigSecretRef `json:"kubeconfigSecretRef"`
+}
+
+// KubeconfigSecretRef represents a kubeconfig stored in a secret in the
+// namespace of the control plane.
+type KubeconfigSecretRef struct {
+	// name of the secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// key of the kubeconfig in the secret. Defaults to kubeconfig.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KarmadaControlPlane is the Schema for the karmadacontrolplanes API
+type KarmadaControlPlane struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KarmadaControlPlaneSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KarmadaControlPlaneList contains a list of KarmadaControlPlane
+type KarmadaControlPlaneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KarmadaControlPlane `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KarmadaControlPlane{}, &KarmadaControlPlaneList{})
+}
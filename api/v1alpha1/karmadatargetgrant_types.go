@@ -0,0 +1,81 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KarmadaTargetGrantSpec defines the desired state of KarmadaTargetGrant
+type KarmadaTargetGrantSpec struct {
+	// namespaces of the policies that may target karmada policies in the
+	// namespace of the grant
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	From []KarmadaTargetGrantFrom `json:"from"`
+
+	// karmada policies in the namespace of the grant that may be targeted
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	To []KarmadaTargetGrantTo `json:"to"`
+}
+
+// KarmadaTargetGrantFrom represents the namespace of the policies that are
+// granted access.
+type KarmadaTargetGrantFrom struct {
+	// namespace of the carbon aware karmada policies
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+}
+
+// KarmadaTargetGrantTo represents the karmada policies that may be targeted.
+type KarmadaTargetGrantTo struct {
+	// name of the propagation policy. All propagation policies in the
+	// namespace may be targeted if empty.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KarmadaTargetGrant allows carbon aware karmada policies in other
+// namespaces to target the propagation policies in its namespace.
+type KarmadaTargetGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KarmadaTargetGrantSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KarmadaTargetGrantList contains a list of KarmadaTargetGrant
+type KarmadaTargetGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KarmadaTargetGrant `json:"items"`
+}
+
+// Permits returns whether the grant allows policies in the namespace to
+// target the propagation policy.
+func (g *KarmadaTargetGrant) Permits(fromNamespace, name string) bool {
+	from := false
+	for _, f := range g.Spec.From {
+		if f.Namespace == fromNamespace {
+			from = true
+			break
+		}
+	}
+	if !from {
+		return false
+	}
+
+	for _, t := range g.Spec.To {
+		if t.Name == "" || t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	SchemeBuilder.Register(&KarmadaTargetGrant{}, &KarmadaTargetGrantList{})
+}
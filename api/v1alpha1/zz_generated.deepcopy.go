@@ -22,7 +22,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -98,6 +99,16 @@ func (in *CarbonAwareKarmadaPolicySpec) DeepCopyInto(out *CarbonAwareKarmadaPoli
 		**out = **in
 	}
 	out.KarmadaTargetRef = in.KarmadaTargetRef
+	if in.EvaluationInterval != nil {
+		in, out := &in.EvaluationInterval, &out.EvaluationInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ControlPlaneRef != nil {
+		in, out := &in.ControlPlaneRef, &out.ControlPlaneRef
+		*out = new(ControlPlaneRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CarbonAwareKarmadaPolicySpec.
@@ -123,6 +134,24 @@ func (in *CarbonAwareKarmadaPolicyStatus) DeepCopyInto(out *CarbonAwareKarmadaPo
 		*out = make([]ClusterStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClusterTiers != nil {
+		in, out := &in.ClusterTiers, &out.ClusterTiers
+		*out = make([][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CarbonAwareKarmadaPolicyStatus.
@@ -181,6 +210,208 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneRef) DeepCopyInto(out *ControlPlaneRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneRef.
+func (in *ControlPlaneRef) DeepCopy() *ControlPlaneRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaControlPlane) DeepCopyInto(out *KarmadaControlPlane) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaControlPlane.
+func (in *KarmadaControlPlane) DeepCopy() *KarmadaControlPlane {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaControlPlane)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KarmadaControlPlane) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaControlPlaneList) DeepCopyInto(out *KarmadaControlPlaneList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KarmadaControlPlane, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaControlPlaneList.
+func (in *KarmadaControlPlaneList) DeepCopy() *KarmadaControlPlaneList {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaControlPlaneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KarmadaControlPlaneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaControlPlaneSpec) DeepCopyInto(out *KarmadaControlPlaneSpec) {
+	*out = *in
+	out.KubeconfigSecretRef = in.KubeconfigSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaControlPlaneSpec.
+func (in *KarmadaControlPlaneSpec) DeepCopy() *KarmadaControlPlaneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaControlPlaneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaTargetGrant) DeepCopyInto(out *KarmadaTargetGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaTargetGrant.
+func (in *KarmadaTargetGrant) DeepCopy() *KarmadaTargetGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaTargetGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KarmadaTargetGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaTargetGrantFrom) DeepCopyInto(out *KarmadaTargetGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaTargetGrantFrom.
+func (in *KarmadaTargetGrantFrom) DeepCopy() *KarmadaTargetGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaTargetGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaTargetGrantList) DeepCopyInto(out *KarmadaTargetGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KarmadaTargetGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaTargetGrantList.
+func (in *KarmadaTargetGrantList) DeepCopy() *KarmadaTargetGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaTargetGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KarmadaTargetGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaTargetGrantSpec) DeepCopyInto(out *KarmadaTargetGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]KarmadaTargetGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]KarmadaTargetGrantTo, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaTargetGrantSpec.
+func (in *KarmadaTargetGrantSpec) DeepCopy() *KarmadaTargetGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaTargetGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaTargetGrantTo) DeepCopyInto(out *KarmadaTargetGrantTo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaTargetGrantTo.
+func (in *KarmadaTargetGrantTo) DeepCopy() *KarmadaTargetGrantTo {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaTargetGrantTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KarmadaTargetRef) DeepCopyInto(out *KarmadaTargetRef) {
 	*out = *in
@@ -195,3 +426,18 @@ func (in *KarmadaTargetRef) DeepCopy() *KarmadaTargetRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeconfigSecretRef) DeepCopyInto(out *KubeconfigSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSecretRef.
+func (in *KubeconfigSecretRef) DeepCopy() *KubeconfigSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeconfigSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
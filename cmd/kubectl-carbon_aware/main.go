@@ -0,0 +1,359 @@
+// kubectl-carbon_aware is a kubectl plugin for inspecting carbon aware
+// placement. Install it on the PATH and run `kubectl carbon-aware`.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+	"github.com/rossf7/carbon-aware-karmada-operator/internal/controller"
+	"github.com/rossf7/carbon-aware-karmada-operator/internal/simulator"
+)
+
+const usage = `Inspect carbon aware placement of CarbonAwareKarmadaPolicies.
+
+Usage:
+  kubectl carbon-aware list [-n NAMESPACE | -A]
+  kubectl carbon-aware rank POLICY [-n NAMESPACE] [-provider-name NAME]
+  kubectl carbon-aware reevaluate POLICY [-n NAMESPACE]
+  kubectl carbon-aware simulate (POLICY | -f FILE) -data FILE [-desired-clusters N] [-baseline CLUSTERS] [-o json]
+
+Commands:
+  list        List policies with their active clusters.
+  rank        Fetch the live carbon intensity for the clusters of a policy and
+              show the ranking the controller would use, including why each
+              cluster is excluded.
+  reevaluate  Trigger the controller to evaluate a policy again.
+  simulate    Replay historical carbon intensity data from a CSV or JSON file
+              through the controller's cluster selection and compare the
+              carbon with a static placement.
+
+The rank command uses the same provider config as the operator e.g. the
+ELECTRICITY_MAP_API_TOKEN and ELECTRICITY_MAP_API_URL env vars.
+
+Flags:
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(carbonawarev1alpha1.AddToScheme(scheme))
+}
+
+type options struct {
+	kubeconfig    string
+	kubeContext   string
+	namespace     string
+	allNamespaces bool
+	providerName  string
+	fetchTimeout  time.Duration
+
+	policyFile      string
+	dataFile        string
+	desiredClusters int
+	baseline        string
+	output          string
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	opts := options{}
+	fs := flag.NewFlagSet("kubectl carbon-aware", flag.ContinueOnError)
+	fs.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	fs.StringVar(&opts.kubeContext, "context", "", "The kubeconfig context to use.")
+	fs.StringVar(&opts.namespace, "n", "", "The namespace of the policy. Defaults to the context namespace.")
+	fs.BoolVar(&opts.allNamespaces, "A", false, "List policies in all namespaces.")
+	fs.StringVar(&opts.providerName, "provider-name", "ElectricityMap", "The carbon intensity provider name.")
+	fs.DurationVar(&opts.fetchTimeout, "fetch-timeout", 10*time.Second, "The timeout for fetching the carbon intensity of a location.")
+	fs.StringVar(&opts.policyFile, "f", "", "Simulate the policy in the YAML file instead of a policy in the cluster.")
+	fs.StringVar(&opts.dataFile, "data", "", "CSV (timestamp,location,value) or JSON file of historical carbon intensity to simulate.")
+	fs.IntVar(&opts.desiredClusters, "desired-clusters", 0, "Override the desired clusters of the simulated policy.")
+	fs.StringVar(&opts.baseline, "baseline", "",
+		"Comma separated clusters of the static baseline placement. Defaults to the first desired clusters of the policy.")
+	fs.StringVar(&opts.output, "o", "", "Output format of simulate. Either empty for text or json.")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return fmt.Errorf("a command is required")
+	}
+
+	ctx := context.Background()
+	command, positional := positional[0], positional[1:]
+
+	// A policy file can be simulated without a cluster.
+	if command == "simulate" && opts.policyFile != "" {
+		return simulate(ctx, nil, types.NamespacedName{}, opts, out)
+	}
+
+	c, namespace, err := newClient(opts)
+	if err != nil {
+		return err
+	}
+	if opts.namespace != "" {
+		namespace = opts.namespace
+	}
+
+	switch command {
+	case "list":
+		if opts.allNamespaces {
+			namespace = ""
+		}
+		return list(ctx, c, namespace, out)
+	case "rank", "reevaluate", "simulate":
+		if len(positional) != 1 {
+			return fmt.Errorf("%s requires a policy name", command)
+		}
+		key := types.NamespacedName{Name: positional[0], Namespace: namespace}
+		switch command {
+		case "rank":
+			return rank(ctx, c, key, opts, out)
+		case "simulate":
+			return simulate(ctx, c, key, opts, out)
+		}
+		return reevaluate(ctx, c, key, out)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// parseInterspersed parses flags that may appear before or after the
+// positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func newClient(opts options) (client.Client, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = opts.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.kubeContext})
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return c, namespace, nil
+}
+
+func list(ctx context.Context, c client.Client, namespace string, out io.Writer) error {
+	policies := &carbonawarev1alpha1.CarbonAwareKarmadaPolicyList{}
+	err := c.List(ctx, policies, client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tDESIRED\tACTIVE CLUSTERS\tTARGET")
+	for _, p := range policies.Items {
+		desired := int32(0)
+		if p.Spec.DesiredClusters != nil {
+			desired = *p.Spec.DesiredClusters
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", p.Namespace, p.Name, desired,
+			strings.Join(p.Status.ActiveClusters, ","), targetName(p))
+	}
+
+	return w.Flush()
+}
+
+func rank(ctx context.Context, c client.Client, key types.NamespacedName, opts options, out io.Writer) error {
+	policy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+	err := c.Get(ctx, key, policy)
+	if err != nil {
+		return fmt.Errorf("failed to get policy: %w", err)
+	}
+
+	fetcher, err := controller.NewCarbonIntensityFetcher(opts.providerName, controller.CacheOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create carbon intensity fetcher: %w", err)
+	}
+
+	clusters, fetchErrors := controller.FetchClusters(ctx, fetcher, policy.Spec.ClusterLocations, 0, opts.fetchTimeout)
+	desiredClusters := 0
+	if policy.Spec.DesiredClusters != nil {
+		desiredClusters = int(*policy.Spec.DesiredClusters)
+	}
+	rankings := controller.RankClusters(clusters, fetchErrors, desiredClusters)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RANK\tCLUSTER\tLOCATION\tCARBON INTENSITY\tACTIVE\tREASON")
+	for i, r := range rankings {
+		intensity := "-"
+		if r.CarbonIntensity.IsValid {
+			intensity = fmt.Sprintf("%.2f %s", r.CarbonIntensity.Value, r.CarbonIntensity.Units)
+		}
+		reason := r.Excluded
+		if r.Message != "" {
+			reason = fmt.Sprintf("%s: %s", reason, r.Message)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\n", i+1, r.ClusterName, r.CarbonIntensity.Location,
+			intensity, r.Active, reason)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	activeClusters := controller.ActiveClusters(rankings)
+	fmt.Fprintf(out, "\nLive active clusters:    %s\n", strings.Join(activeClusters, ","))
+	fmt.Fprintf(out, "Current active clusters: %s\n", strings.Join(policy.Status.ActiveClusters, ","))
+
+	return nil
+}
+
+func reevaluate(ctx context.Context, c client.Client, key types.NamespacedName, out io.Writer) error {
+	policy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+	err := c.Get(ctx, key, policy)
+	if err != nil {
+		return fmt.Errorf("failed to get policy: %w", err)
+	}
+
+	patch := client.MergeFrom(policy.DeepCopy())
+	annotations := policy.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[carbonawarev1alpha1.ReevaluateAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	policy.SetAnnotations(annotations)
+
+	err = c.Patch(ctx, policy, patch)
+	if err != nil {
+		return fmt.Errorf("failed to patch policy: %w", err)
+	}
+	fmt.Fprintf(out, "carbonawarekarmadapolicy/%s re-evaluation requested\n", key.Name)
+
+	return nil
+}
+
+func simulate(ctx context.Context, c client.Client, key types.NamespacedName, opts options, out io.Writer) error {
+	if opts.dataFile == "" {
+		return fmt.Errorf("simulate requires a -data file")
+	}
+
+	policy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+	if opts.policyFile != "" {
+		data, err := os.ReadFile(opts.policyFile)
+		if err != nil {
+			return err
+		}
+		err = yaml.Unmarshal(data, policy)
+		if err != nil {
+			return fmt.Errorf("failed to decode policy %s: %w", opts.policyFile, err)
+		}
+	} else {
+		err := c.Get(ctx, key, policy)
+		if err != nil {
+			return fmt.Errorf("failed to get policy: %w", err)
+		}
+	}
+
+	desiredClusters := opts.desiredClusters
+	if desiredClusters == 0 && policy.Spec.DesiredClusters != nil {
+		desiredClusters = int(*policy.Spec.DesiredClusters)
+	}
+	baseline := []string{}
+	if opts.baseline != "" {
+		baseline = strings.Split(opts.baseline, ",")
+	}
+
+	samples, err := simulator.LoadSamples(opts.dataFile)
+	if err != nil {
+		return err
+	}
+	result, err := simulator.Simulate(policy.Spec.ClusterLocations, desiredClusters, baseline, samples)
+	if err != nil {
+		return err
+	}
+
+	switch opts.output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case "":
+	default:
+		return fmt.Errorf("unsupported output format %q", opts.output)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIMESTAMP\tACTIVE CLUSTERS\tADDED\tREMOVED")
+	for _, change := range result.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Timestamp.Format(time.RFC3339), strings.Join(change.ActiveClusters, ","),
+			strings.Join(change.Added, ","), strings.Join(change.Removed, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nPeriod:            %s to %s (%d steps)\n",
+		result.Start.Format(time.RFC3339), result.End.Format(time.RFC3339), result.Steps)
+	fmt.Fprintf(out, "Desired clusters:  %d\n", desiredClusters)
+	fmt.Fprintf(out, "Placement changes: %d\n", len(result.Changes)-1)
+	fmt.Fprintf(out, "Migrations:        %d\n", result.Migrations)
+	fmt.Fprintf(out, "Baseline clusters: %s\n", strings.Join(result.BaselineClusters, ","))
+	fmt.Fprintf(out, "Baseline carbon:   %.2f gCO2e per kW\n", result.Baseline)
+	fmt.Fprintf(out, "Carbon aware:      %.2f gCO2e per kW\n", result.CarbonAware)
+	fmt.Fprintf(out, "Carbon saved:      %.2f gCO2e per kW (%.1f%%)\n", result.Saved, result.SavedPercent)
+
+	return nil
+}
+
+func targetName(p carbonawarev1alpha1.CarbonAwareKarmadaPolicy) string {
+	kind := strings.SplitN(string(p.Spec.KarmadaTarget), ".", 2)[0]
+	if p.Spec.KarmadaTarget == carbonawarev1alpha1.ClusterPropagationPolicy {
+		return fmt.Sprintf("%s/%s", kind, p.Spec.KarmadaTargetRef.Name)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", kind, p.Spec.KarmadaTargetRef.Namespace, p.Spec.KarmadaTargetRef.Name)
+}
@@ -1,23 +1,43 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
 	"github.com/rossf7/carbon-aware-karmada-operator/internal/controller"
+	"github.com/rossf7/carbon-aware-karmada-operator/internal/exporter"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -30,20 +50,95 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(carbonawarev1alpha1.AddToScheme(scheme))
+	utilruntime.Must(controller.AddKarmadaToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "exporter" {
+		runExporter(os.Args[2:])
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var providerName string
+	var fetchConcurrency int
+	var fetchTimeout time.Duration
+	var maxConcurrentReconciles int
+	var maxStaleness time.Duration
+	var cacheConfigMap string
+	var cacheConfigMapNamespace string
+	var cacheMinTTL time.Duration
+	var cacheMaxTTL time.Duration
+	var cacheNegativeTTL time.Duration
+	var cachePersistInterval time.Duration
+	var resilienceOptions controller.ResilienceOptions
+	var refreshBeforeExpiry time.Duration
+	var minRequeueInterval time.Duration
+	var maxRequeueInterval time.Duration
+	var requeueJitter float64
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var traceSampleRatio float64
+	var enableRankingAPI bool
+	var karmadaKubeconfig string
+	var watchNamespaces string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.StringVar(&providerName, "provider-name", "ElectricityMap", "The grid-intensity-go provider name.")
+	flag.StringVar(&providerName, "provider-name", "ElectricityMap", "The carbon intensity provider name. Either Static or a grid-intensity-go provider name.")
+	flag.IntVar(&fetchConcurrency, "fetch-concurrency", 4, "The maximum number of locations fetched in parallel for a policy.")
+	flag.DurationVar(&fetchTimeout, "fetch-timeout", 10*time.Second, "The timeout for fetching the carbon intensity of a location.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "The maximum number of policies reconciled in parallel.")
+	flag.DurationVar(&maxStaleness, "max-staleness", time.Hour,
+		"How long after it expires the last known carbon intensity is used if the provider is unavailable.")
+	flag.StringVar(&cacheConfigMap, "cache-configmap", "",
+		"The name of the ConfigMap used to persist the carbon intensity cache. Disabled if empty.")
+	flag.StringVar(&cacheConfigMapNamespace, "cache-configmap-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace of the cache ConfigMap. Defaults to the POD_NAMESPACE env var.")
+	flag.DurationVar(&cacheMinTTL, "cache-min-ttl", time.Minute, "The minimum time carbon intensity readings are cached.")
+	flag.DurationVar(&cacheMaxTTL, "cache-max-ttl", 2*time.Hour, "The maximum time carbon intensity readings are cached.")
+	flag.DurationVar(&cacheNegativeTTL, "cache-negative-ttl", 15*time.Minute,
+		"How long invalid readings and unknown locations are cached.")
+	flag.DurationVar(&cachePersistInterval, "cache-persist-interval", 30*time.Second,
+		"How often changes to the carbon intensity cache are saved to the cache ConfigMap.")
+	flag.Float64Var(&resilienceOptions.RateLimit, "provider-rate-limit", 1,
+		"The maximum requests per second to the carbon intensity provider. Zero disables rate limiting.")
+	flag.IntVar(&resilienceOptions.Burst, "provider-rate-limit-burst", 5, "The burst size of the provider rate limiter.")
+	flag.IntVar(&resilienceOptions.MaxRetries, "provider-max-retries", 3, "The number of retries for retryable provider errors.")
+	flag.DurationVar(&resilienceOptions.RetryBaseDelay, "provider-retry-base-delay", 500*time.Millisecond,
+		"The base delay for exponential backoff between provider retries.")
+	flag.DurationVar(&resilienceOptions.RetryMaxDelay, "provider-retry-max-delay", 5*time.Second,
+		"The maximum delay between provider retries.")
+	flag.IntVar(&resilienceOptions.BreakerFailures, "circuit-breaker-failures", 5,
+		"The number of consecutive provider failures that opens the circuit breaker. Zero disables it.")
+	flag.DurationVar(&resilienceOptions.BreakerOpenDuration, "circuit-breaker-open-duration", time.Minute,
+		"How long the circuit breaker stays open before a trial request is allowed.")
+	flag.DurationVar(&refreshBeforeExpiry, "refresh-before-expiry", 2*time.Minute,
+		"How long before it expires carbon intensity data is refreshed in the background. Zero disables it.")
+	flag.DurationVar(&minRequeueInterval, "min-requeue-interval", time.Minute,
+		"The minimum interval before a policy is evaluated again.")
+	flag.DurationVar(&maxRequeueInterval, "max-requeue-interval", time.Hour,
+		"The maximum interval before a policy is evaluated again.")
+	flag.Float64Var(&requeueJitter, "requeue-jitter", 0.1,
+		"The maximum fraction of the requeue interval added as jitter.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The OTLP gRPC endpoint traces are exported to e.g. otel-collector:4317. Tracing is disabled if empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Disable TLS for the OTLP endpoint.")
+	flag.Float64Var(&traceSampleRatio, "trace-sample-ratio", 1, "The fraction of reconciles that are traced.")
+	flag.BoolVar(&enableRankingAPI, "enable-ranking-api", true,
+		"Serve the read only ranking API on the metrics endpoint.")
+	flag.StringVar(&karmadaKubeconfig, "karmada-kubeconfig", "",
+		"Path to a kubeconfig for the Karmada API server. Karmada policies are managed through it "+
+			"while policies, leader election and events stay in the cluster the operator runs in. "+
+			"Defaults to the operator cluster.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma separated list of namespaces to watch. The operator then only needs namespaced RBAC and "+
+			"ClusterPropagationPolicy targets are rejected. All namespaces are watched if empty.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -52,11 +147,57 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: metricsAddr,
-		},
+	ctx := ctrl.SetupSignalHandler()
+
+	if otlpEndpoint != "" {
+		shutdownTracing, err := setupTracing(ctx, otlpEndpoint, otlpInsecure, traceSampleRatio)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				setupLog.Error(err, "unable to flush traces")
+			}
+		}()
+	}
+
+	// The ranking API is served by the metrics server so it is protected
+	// by the same auth proxy as the metrics. It reads the policy status from
+	// the manager cache so every replica can serve it.
+	var rankingAPI *controller.RankingAPI
+	metricsOptions := metricsserver.Options{
+		BindAddress: metricsAddr,
+	}
+	if enableRankingAPI {
+		rankingAPI = &controller.RankingAPI{}
+		metricsOptions.ExtraHandlers = map[string]http.Handler{
+			controller.RankingAPIPath: rankingAPI,
+		}
+	}
+
+	// In the namespaced mode the caches only list and watch objects in the
+	// watched namespaces.
+	var namespaces []string
+	managerCacheOptions := cache.Options{}
+	if watchNamespaces != "" {
+		managerCacheOptions.DefaultNamespaces = map[string]cache.Config{}
+		for _, ns := range strings.Split(watchNamespaces, ",") {
+			ns = strings.TrimSpace(ns)
+			if ns == "" {
+				continue
+			}
+			namespaces = append(namespaces, ns)
+			managerCacheOptions.DefaultNamespaces[ns] = cache.Config{}
+		}
+		setupLog.Info("watching namespaces", "namespaces", namespaces)
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:                 scheme,
+		Cache:                  managerCacheOptions,
+		Metrics:                metricsOptions,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "7e1e6420.rossf7.github.io",
@@ -76,22 +217,104 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if rankingAPI != nil {
+		rankingAPI.Reader = mgr.GetClient()
+	}
+
+	cacheOptions := controller.CacheOptions{
+		MinTTL:          cacheMinTTL,
+		MaxTTL:          cacheMaxTTL,
+		NegativeTTL:     cacheNegativeTTL,
+		PersistInterval: cachePersistInterval,
+	}
+	if cacheConfigMap != "" {
+		cacheOptions.Store = controller.NewConfigMapCacheStore(mgr.GetClient(), mgr.GetAPIReader(),
+			types.NamespacedName{Name: cacheConfigMap, Namespace: cacheConfigMapNamespace})
+	}
 
-	carbonIntensityFetcher, err := controller.NewGridIntensityFetcher(providerName)
+	var carbonIntensityFetcher controller.CarbonIntensityFetcher
+	carbonIntensityFetcher, err = controller.NewCarbonIntensityFetcher(providerName, cacheOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to create carbon intensity fetcher")
 		os.Exit(1)
 	}
+	if runnable, ok := carbonIntensityFetcher.(manager.Runnable); ok {
+		if err := mgr.Add(runnable); err != nil {
+			setupLog.Error(err, "unable to add carbon intensity fetcher to manager")
+			os.Exit(1)
+		}
+	}
+	carbonIntensityFetcher = controller.NewResilientFetcher(carbonIntensityFetcher, resilienceOptions)
+	carbonIntensityFetcher = controller.NewLastKnownGoodFetcher(carbonIntensityFetcher, maxStaleness)
+
+	var refresher *controller.Refresher
+	if refreshBeforeExpiry > 0 {
+		refresher = controller.NewRefresher(carbonIntensityFetcher, refreshBeforeExpiry, fetchTimeout)
+		if err := mgr.Add(refresher); err != nil {
+			setupLog.Error(err, "unable to add carbon intensity refresher to manager")
+			os.Exit(1)
+		}
+	}
+
+	karmadaConfig := restConfig
+	var karmadaCluster cluster.Cluster
+	if karmadaKubeconfig != "" {
+		karmadaConfig, err = clientcmd.BuildConfigFromFlags("", karmadaKubeconfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load karmada kubeconfig", "path", karmadaKubeconfig)
+			os.Exit(1)
+		}
+		karmadaCluster, err = cluster.New(karmadaConfig, func(o *cluster.Options) {
+			o.Scheme = scheme
+			o.Cache = managerCacheOptions
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create karmada cluster client")
+			os.Exit(1)
+		}
+		if err := mgr.Add(karmadaCluster); err != nil {
+			setupLog.Error(err, "unable to add karmada cluster to manager")
+			os.Exit(1)
+		}
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(karmadaConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	karmadaPreflight := controller.NewKarmadaPreflight(discoveryClient, 0)
+	if err := mgr.Add(karmadaPreflight); err != nil {
+		setupLog.Error(err, "unable to add karmada preflight to manager")
+		os.Exit(1)
+	}
 
 	if err = (&controller.CarbonAwareKarmadaPolicyReconciler{
-		Client:                 mgr.GetClient(),
-		Scheme:                 mgr.GetScheme(),
-		Recorder:               mgr.GetEventRecorderFor("carbon-aware-karmada-operator"),
-		CarbonIntensityFetcher: carbonIntensityFetcher,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("carbon-aware-karmada-operator"),
+		CarbonIntensityFetcher:  carbonIntensityFetcher,
+		FetchConcurrency:        fetchConcurrency,
+		FetchTimeout:            fetchTimeout,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		Refresher:               refresher,
+		MinRequeueInterval:      minRequeueInterval,
+		MaxRequeueInterval:      maxRequeueInterval,
+		RequeueJitter:           requeueJitter,
+		KarmadaPreflight:        karmadaPreflight,
+		KarmadaCluster:          karmadaCluster,
+		ControlPlanes:           controller.NewControlPlaneClients(mgr.GetAPIReader(), mgr.GetScheme()),
+		WatchNamespaces:         namespaces,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CarbonAwareKarmadaPolicy")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CarbonAwareKarmadaPolicy")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -102,10 +325,142 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("karmada-crds", karmadaPreflight.Check); err != nil {
+		setupLog.Error(err, "unable to set up karmada crds ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// setupTracing sets the global tracer provider to export spans to the OTLP
+// endpoint. The returned function flushes and stops the exporter.
+func setupTracing(ctx context.Context, endpoint string, insecure bool, sampleRatio float64) (func(context.Context) error, error) {
+	clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("carbon-aware-karmada-operator")))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// runExporter runs the standalone exporter. It exposes carbon intensity
+// metrics for a list of locations and needs no access to Kubernetes.
+func runExporter(args []string) {
+	var metricsAddr string
+	var providerName string
+	var locations string
+	var interval time.Duration
+	var fetchTimeout time.Duration
+	var resilienceOptions controller.ResilienceOptions
+	fs := flag.NewFlagSet("exporter", flag.ExitOnError)
+	fs.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	fs.StringVar(&providerName, "provider-name", "ElectricityMap", "The carbon intensity provider name. Either Static or a grid-intensity-go provider name.")
+	fs.StringVar(&locations, "locations", "", "Comma separated list of locations to export.")
+	fs.DurationVar(&interval, "interval", 5*time.Minute, "How often the carbon intensity of the locations is fetched.")
+	fs.DurationVar(&fetchTimeout, "fetch-timeout", 10*time.Second, "The timeout for fetching the carbon intensity of a location.")
+	fs.Float64Var(&resilienceOptions.RateLimit, "provider-rate-limit", 1,
+		"The maximum requests per second to the carbon intensity provider. Zero disables rate limiting.")
+	fs.IntVar(&resilienceOptions.Burst, "provider-rate-limit-burst", 5, "The burst size of the provider rate limiter.")
+	fs.IntVar(&resilienceOptions.MaxRetries, "provider-max-retries", 3, "The number of retries for retryable provider errors.")
+	fs.DurationVar(&resilienceOptions.RetryBaseDelay, "provider-retry-base-delay", 500*time.Millisecond,
+		"The base delay for exponential backoff between provider retries.")
+	fs.DurationVar(&resilienceOptions.RetryMaxDelay, "provider-retry-max-delay", 5*time.Second,
+		"The maximum delay between provider retries.")
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(fs)
+	_ = fs.Parse(args)
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	exporterLog := ctrl.Log.WithName("exporter")
+
+	locationList := []string{}
+	for _, location := range strings.Split(locations, ",") {
+		if location = strings.TrimSpace(location); location != "" {
+			locationList = append(locationList, location)
+		}
+	}
+	if len(locationList) == 0 {
+		exporterLog.Error(errors.New("no locations"), "the -locations flag is required")
+		os.Exit(1)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	fetcher, err := controller.NewCarbonIntensityFetcher(providerName, controller.CacheOptions{})
+	if err != nil {
+		exporterLog.Error(err, "unable to create carbon intensity fetcher")
+		os.Exit(1)
+	}
+	if runnable, ok := fetcher.(manager.Runnable); ok {
+		go func() {
+			if err := runnable.Start(ctx); err != nil {
+				exporterLog.Error(err, "carbon intensity fetcher stopped")
+			}
+		}()
+	}
+	forecaster, _ := fetcher.(controller.CarbonIntensityForecaster)
+
+	e := exporter.New(controller.NewResilientFetcher(fetcher, resilienceOptions), forecaster,
+		locationList, interval, fetchTimeout, exporterLog)
+	// The exporter has its own registry so it does not expose the
+	// controller metrics registered with the controller-runtime registry.
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	registry.MustRegister(controller.ProviderMetrics()...)
+	if err := e.Register(registry); err != nil {
+		exporterLog.Error(err, "unable to register metrics")
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{
+		Addr:              metricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		if err := e.Start(ctx); err != nil {
+			exporterLog.Error(err, "exporter stopped")
+		}
+	}()
+
+	exporterLog.Info("starting exporter", "provider", providerName, "locations", locationList)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		exporterLog.Error(err, "problem running exporter")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	cacheConfigMapKey = "cache.json"
+)
+
+// CacheStore persists cached carbon intensity readings so the cache can be
+// warmed after a restart or leader failover.
+type CacheStore interface {
+	Load(ctx context.Context) (map[string]CarbonIntensity, error)
+	Save(ctx context.Context, entries map[string]CarbonIntensity) error
+}
+
+// ConfigMapCacheStore stores the cache as JSON in a ConfigMap. It only needs
+// get, create and update on the ConfigMap which are granted in its
+// namespace by config/rbac/cache_role.yaml.
+type ConfigMapCacheStore struct {
+	client client.Client
+	reader client.Reader
+	key    types.NamespacedName
+
+	mu sync.Mutex
+}
+
+// NewConfigMapCacheStore creates a store for the ConfigMap. The reader
+// should not be backed by the manager cache so the store can be loaded
+// before the cache has started.
+func NewConfigMapCacheStore(c client.Client, reader client.Reader, key types.NamespacedName) *ConfigMapCacheStore {
+	return &ConfigMapCacheStore{
+		client: c,
+		reader: reader,
+		key:    key,
+	}
+}
+
+func (c *ConfigMapCacheStore) Load(ctx context.Context) (map[string]CarbonIntensity, error) {
+	entries := map[string]CarbonIntensity{}
+
+	configMap := &corev1.ConfigMap{}
+	err := c.reader.Get(ctx, c.key, configMap)
+	if apierrors.IsNotFound(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get cache configmap: %w", err)
+	}
+
+	data, ok := configMap.Data[cacheConfigMapKey]
+	if !ok {
+		return entries, nil
+	}
+	err = json.Unmarshal([]byte(data), &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cache configmap: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (c *ConfigMapCacheStore) Save(ctx context.Context, entries map[string]CarbonIntensity) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = c.reader.Get(ctx, c.key, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.key.Name,
+				Namespace: c.key.Namespace,
+			},
+			Data: map[string]string{
+				cacheConfigMapKey: string(data),
+			},
+		}
+		err = c.client.Create(ctx, configMap)
+		if err != nil {
+			return fmt.Errorf("failed to create cache configmap: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get cache configmap: %w", err)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[cacheConfigMapKey] = string(data)
+	err = c.client.Update(ctx, configMap)
+	if err != nil {
+		return fmt.Errorf("failed to update cache configmap: %w", err)
+	}
+
+	return nil
+}
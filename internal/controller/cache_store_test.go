@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var testCacheKey = types.NamespacedName{Name: "carbon-intensity-cache", Namespace: "default"}
+
+func TestConfigMapCacheStore(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().Build()
+	store := NewConfigMapCacheStore(c, c, testCacheKey)
+
+	entries, err := store.Load(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(BeEmpty())
+
+	validFrom := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	de := CarbonIntensity{
+		IsValid:   true,
+		Location:  "DE",
+		Units:     defaultUnits,
+		ValidFrom: validFrom,
+		ValidTo:   validFrom.Add(time.Hour),
+		Value:     380,
+	}
+	g.Expect(store.Save(ctx, map[string]CarbonIntensity{"DE": de})).To(Succeed())
+
+	entries, err = store.Load(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(Equal(map[string]CarbonIntensity{"DE": de}))
+
+	fr := de
+	fr.Location, fr.Value = "FR", 60
+	g.Expect(store.Save(ctx, map[string]CarbonIntensity{"DE": de, "FR": fr})).To(Succeed())
+
+	entries, err = store.Load(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(Equal(map[string]CarbonIntensity{"DE": de, "FR": fr}))
+}
+
+func TestConfigMapCacheStoreInvalidData(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testCacheKey.Name, Namespace: testCacheKey.Namespace},
+		Data: map[string]string{
+			"other":           "kept",
+			cacheConfigMapKey: "{not json",
+		},
+	}).Build()
+	store := NewConfigMapCacheStore(c, c, testCacheKey)
+
+	_, err := store.Load(ctx)
+	g.Expect(err).To(HaveOccurred())
+
+	// Saving replaces the invalid data and keeps other keys.
+	g.Expect(store.Save(ctx, map[string]CarbonIntensity{})).To(Succeed())
+	configMap := &corev1.ConfigMap{}
+	g.Expect(c.Get(ctx, testCacheKey, configMap)).To(Succeed())
+	g.Expect(configMap.Data).To(Equal(map[string]string{"other": "kept", cacheConfigMapKey: "{}"}))
+}
+
+type countingCacheStore struct {
+	mu     sync.Mutex
+	loaded map[string]CarbonIntensity
+	saves  []map[string]CarbonIntensity
+}
+
+func (s *countingCacheStore) Load(ctx context.Context) (map[string]CarbonIntensity, error) {
+	return s.loaded, nil
+}
+
+func (s *countingCacheStore) Save(ctx context.Context, entries map[string]CarbonIntensity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saves = append(s.saves, entries)
+	return nil
+}
+
+func (s *countingCacheStore) saved() []map[string]CarbonIntensity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]map[string]CarbonIntensity{}, s.saves...)
+}
+
+func TestGridIntensityFetcherPersistsInBackground(t *testing.T) {
+	g := NewWithT(t)
+
+	store := &countingCacheStore{}
+	fetcher := newFakeGridIntensityFetcher(&fakeGridProvider{})
+	fetcher.store = store
+	fetcher.persistInterval = 20 * time.Millisecond
+
+	for _, location := range []string{"DE", "FR"} {
+		_, err := fetcher.Fetch(context.Background(), "member1", location)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	// Fetches do not write to the store.
+	g.Expect(store.saved()).To(BeEmpty())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = fetcher.Start(ctx)
+	}()
+
+	g.Eventually(store.saved).Should(HaveLen(1))
+	g.Expect(store.saved()[0]).To(HaveKey("DE"))
+	g.Expect(store.saved()[0]).To(HaveKey("FR"))
+
+	// The cache is not saved again until it changes.
+	g.Consistently(store.saved, 100*time.Millisecond).Should(HaveLen(1))
+
+	_, err := fetcher.Fetch(withoutCache(context.Background()), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(store.saved).Should(HaveLen(2))
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
+
+func TestGridIntensityFetcherPersistsOnShutdown(t *testing.T) {
+	g := NewWithT(t)
+
+	store := &countingCacheStore{}
+	fetcher := newFakeGridIntensityFetcher(&fakeGridProvider{})
+	fetcher.store = store
+	fetcher.persistInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = fetcher.Start(ctx)
+	}()
+
+	_, err := fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(store.saved()).To(BeEmpty())
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+	g.Expect(store.saved()).To(HaveLen(1))
+	g.Expect(store.saved()[0]).To(HaveKey("DE"))
+}
+
+func TestGridIntensityFetcherWarmsCacheOnStart(t *testing.T) {
+	g := NewWithT(t)
+
+	de := CarbonIntensity{IsValid: true, Location: "DE", Units: defaultUnits, ValidTo: time.Now().Add(time.Hour), Value: 123}
+	expired := CarbonIntensity{IsValid: true, Location: "FR", Units: defaultUnits, ValidTo: time.Now().Add(-time.Minute), Value: 45}
+	store := &countingCacheStore{loaded: map[string]CarbonIntensity{"DE": de, "FR": expired}}
+	provider := &fakeGridProvider{}
+	fetcher := newFakeGridIntensityFetcher(provider)
+	fetcher.store = store
+	fetcher.persistInterval = time.Hour
+	fetcher.warmed = make(chan struct{})
+
+	// Fetches wait for the cache to be warmed.
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelWait()
+	_, err := fetcher.Fetch(waitCtx, "member1", "DE")
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = fetcher.Start(ctx)
+	}()
+
+	result, err := fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity).To(Equal(de))
+	g.Expect(provider.calls).To(Equal(0))
+
+	// Expired entries are not loaded.
+	g.Expect(fetcher.IsCached("FR")).To(BeFalse())
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CarbonAwareSDKProvider = "CarbonAwareSDK"
+
+	// carbonAwareSDKLookback is how far back to query emissions so the
+	// current data point is included.
+	carbonAwareSDKLookback = 2 * time.Hour
+)
+
+// CarbonIntensityForecaster is implemented by fetchers whose provider
+// supports forecasts.
+type CarbonIntensityForecaster interface {
+	Forecast(ctx context.Context, location string) ([]CarbonIntensity, error)
+}
+
+// CarbonAwareSDKFetcher fetches carbon intensity from the Green Software
+// Foundation Carbon Aware SDK WebAPI.
+type CarbonAwareSDKFetcher struct {
+	apiURL string
+	client *http.Client
+	now    func() time.Time
+}
+
+type carbonAwareSDKEmissionsData struct {
+	Location string    `json:"location"`
+	Time     time.Time `json:"time"`
+	Rating   float64   `json:"rating"`
+	Duration string    `json:"duration"`
+}
+
+type carbonAwareSDKForecast struct {
+	Location     string                        `json:"location"`
+	ForecastData []carbonAwareSDKForecastPoint `json:"forecastData"`
+}
+
+type carbonAwareSDKForecastPoint struct {
+	Location  string    `json:"location"`
+	Timestamp time.Time `json:"timestamp"`
+	// Duration is in minutes.
+	Duration int     `json:"duration"`
+	Value    float64 `json:"value"`
+}
+
+func NewCarbonAwareSDKFetcher(apiURL string, client *http.Client) (*CarbonAwareSDKFetcher, error) {
+	if _, err := url.ParseRequestURI(apiURL); err != nil {
+		return nil, fmt.Errorf("invalid carbon aware sdk api url: %w", err)
+	}
+	if client == nil {
+		client = newDefaultHTTPClient()
+	}
+
+	return &CarbonAwareSDKFetcher{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+func (c *CarbonAwareSDKFetcher) Fetch(ctx context.Context, clusterName, location string) (_ ClusterCarbonIntensity, err error) {
+	ctx, span := startFetchSpan(ctx, "CarbonAwareSDKFetcher.Fetch", clusterName, location, CarbonAwareSDKProvider)
+	defer func() { endSpan(span, err) }()
+
+	carbonIntensity, err := c.fetch(ctx, location)
+	if err != nil {
+		return ClusterCarbonIntensity{}, err
+	}
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: carbonIntensity,
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (c *CarbonAwareSDKFetcher) Provider() string {
+	return CarbonAwareSDKProvider
+}
+
+// Forecast returns the current forecast for the location ordered by time.
+func (c *CarbonAwareSDKFetcher) Forecast(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	query := url.Values{}
+	query.Set("location", location)
+
+	var forecasts []carbonAwareSDKForecast
+	found, err := c.get(ctx, "/emissions/forecasts/current", query, &forecasts)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	results := []CarbonIntensity{}
+	for _, f := range forecasts {
+		for _, p := range f.ForecastData {
+			results = append(results, CarbonIntensity{
+				IsValid:   true,
+				Location:  location,
+				Units:     defaultUnits,
+				ValidFrom: p.Timestamp,
+				ValidTo:   p.Timestamp.Add(time.Duration(p.Duration) * time.Minute),
+				Value:     p.Value,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// fetch returns the most recent emissions data for the location. If there
+// is no current data the forecast for the current time is used.
+func (c *CarbonAwareSDKFetcher) fetch(ctx context.Context, location string) (CarbonIntensity, error) {
+	now := c.now().UTC()
+
+	query := url.Values{}
+	query.Set("location", location)
+	query.Set("time", now.Add(-carbonAwareSDKLookback).Format(time.RFC3339))
+	query.Set("toTime", now.Format(time.RFC3339))
+
+	var emissions []carbonAwareSDKEmissionsData
+	found, err := c.get(ctx, "/emissions/bylocation", query, &emissions)
+	if err != nil {
+		return CarbonIntensity{}, err
+	}
+	if !found {
+		return CarbonIntensity{IsValid: false, Location: location}, nil
+	}
+
+	var latest *carbonAwareSDKEmissionsData
+	for i, e := range emissions {
+		if e.Time.After(now) {
+			continue
+		}
+		if latest == nil || e.Time.After(latest.Time) {
+			latest = &emissions[i]
+		}
+	}
+	if latest != nil {
+		duration, err := parseTimeSpan(latest.Duration)
+		if err != nil {
+			return CarbonIntensity{}, fmt.Errorf("invalid duration for %s: %w", location, err)
+		}
+
+		return CarbonIntensity{
+			IsValid:   true,
+			Location:  location,
+			Units:     defaultUnits,
+			ValidFrom: latest.Time,
+			ValidTo:   latest.Time.Add(duration),
+			Value:     latest.Rating,
+		}, nil
+	}
+
+	forecast, err := c.Forecast(ctx, location)
+	if err != nil {
+		return CarbonIntensity{}, err
+	}
+	for _, f := range forecast {
+		if !f.ValidFrom.After(now) && f.ValidTo.After(now) {
+			return f, nil
+		}
+	}
+
+	return CarbonIntensity{IsValid: false, Location: location}, nil
+}
+
+// get decodes the JSON response into result. It returns false if the API
+// does not have data for the request.
+func (c *CarbonAwareSDKFetcher) get(ctx context.Context, path string, query url.Values, result interface{}) (bool, error) {
+	reqURL := c.apiURL + path + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w: %w", path, ErrProviderTransport, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		// The SDK returns 400 bad request for unknown locations which is
+		// classified as ErrUnknownLocation.
+		err = fmt.Errorf("received status %d", resp.StatusCode)
+		if typedErr := statusCodeError(resp.StatusCode); typedErr != nil {
+			err = fmt.Errorf("%w: %w", typedErr, err)
+		}
+		return false, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(result)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// parseTimeSpan parses a .NET TimeSpan in the format [d.]hh:mm:ss[.fffffff]
+// as returned by the Carbon Aware SDK.
+func parseTimeSpan(s string) (time.Duration, error) {
+	var days int
+
+	if i := strings.Index(s, "."); i >= 0 && i < strings.Index(s, ":") {
+		d, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, err
+		}
+		days, s = d, s[i+1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("timespan %q must be in the format hh:mm:ss", s)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(days)*24*time.Hour +
+		time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), nil
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func newCarbonAwareSDKStandIn(emissions, forecast string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("location") != "westeurope" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/emissions/bylocation":
+			fmt.Fprint(w, emissions)
+		case "/emissions/forecasts/current":
+			fmt.Fprint(w, forecast)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCarbonAwareSDKFetcher(t *testing.T) {
+	now := time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)
+	forecast := `[{"location":"westeurope","forecastData":[
+		{"location":"westeurope","timestamp":"2023-09-01T10:25:00Z","duration":5,"value":210.5},
+		{"location":"westeurope","timestamp":"2023-09-01T10:30:00Z","duration":5,"value":190.25}]}]`
+
+	tests := []struct {
+		name        string
+		location    string
+		emissions   string
+		expected    CarbonIntensity
+		expectedErr error
+	}{
+		{
+			name:     "latest emissions",
+			location: "westeurope",
+			emissions: `[
+				{"location":"westeurope","time":"2023-09-01T09:00:00Z","rating":320.1,"duration":"01:00:00"},
+				{"location":"westeurope","time":"2023-09-01T10:00:00Z","rating":301.7,"duration":"01:00:00"}]`,
+			expected: CarbonIntensity{
+				IsValid:   true,
+				Location:  "westeurope",
+				Units:     defaultUnits,
+				ValidFrom: time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC),
+				ValidTo:   time.Date(2023, 9, 1, 11, 0, 0, 0, time.UTC),
+				Value:     301.7,
+			},
+		},
+		{
+			name:      "forecast when no emissions",
+			location:  "westeurope",
+			emissions: `[]`,
+			expected: CarbonIntensity{
+				IsValid:   true,
+				Location:  "westeurope",
+				Units:     defaultUnits,
+				ValidFrom: time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC),
+				ValidTo:   time.Date(2023, 9, 1, 10, 35, 0, 0, time.UTC),
+				Value:     190.25,
+			},
+		},
+		{
+			name:        "unknown location",
+			location:    "atlantis",
+			emissions:   `[]`,
+			expectedErr: ErrUnknownLocation,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			server := newCarbonAwareSDKStandIn(tc.emissions, forecast)
+			defer server.Close()
+
+			fetcher, err := NewCarbonAwareSDKFetcher(server.URL, server.Client())
+			g.Expect(err).NotTo(HaveOccurred())
+			fetcher.now = func() time.Time { return now }
+
+			result, err := fetcher.Fetch(context.Background(), "member1", tc.location)
+			if tc.expectedErr != nil {
+				g.Expect(errors.Is(err, tc.expectedErr)).To(BeTrue(), "unexpected error %v", err)
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.ClusterName).To(Equal("member1"))
+			g.Expect(result.CarbonIntensity).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestParseTimeSpan(t *testing.T) {
+	g := NewWithT(t)
+
+	d, err := parseTimeSpan("01:00:00")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(d).To(Equal(time.Hour))
+
+	d, err = parseTimeSpan("1.00:05:30.5")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(d).To(Equal(24*time.Hour + 5*time.Minute + 30500*time.Millisecond))
+
+	_, err = parseTimeSpan("5 minutes")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -5,13 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
 	gridprovider "github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultUnits = "gCO2e/kWh"
+
+	defaultCacheMinTTL      = time.Minute
+	defaultCacheMaxTTL      = 2 * time.Hour
+	defaultCacheNegativeTTL = 15 * time.Minute
+
+	defaultCachePersistInterval = 30 * time.Second
+	cachePersistShutdownTimeout = 5 * time.Second
+	cacheWarmTimeout            = 30 * time.Second
 )
 
 type CarbonIntensity struct {
+	IsStale   bool
 	IsValid   bool
 	Location  string
 	Units     string
@@ -30,13 +46,89 @@ type CarbonIntensityFetcher interface {
 	Provider() string
 }
 
+type withoutCacheKey struct{}
+
+// withoutCache returns a context that makes fetchers skip cached readings
+// and fetch from the provider. The result is still cached.
+func withoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutCacheKey{}, true)
+}
+
+func isWithoutCache(ctx context.Context) bool {
+	v, _ := ctx.Value(withoutCacheKey{}).(bool)
+	return v
+}
+
+// NewCarbonIntensityFetcher returns the fetcher for the provider name.
+// Providers not implemented by this package are fetched using grid-intensity-go.
+func NewCarbonIntensityFetcher(providerName string, cacheOptions CacheOptions) (CarbonIntensityFetcher, error) {
+	switch providerName {
+	case StaticProvider:
+		path, err := getEnvVar("STATIC_DATA_FILE")
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticFetcher(path)
+	case HTTPProvider:
+		return newHTTPFetcherFromEnv()
+	case CarbonAwareSDKProvider:
+		apiURL, err := getEnvVar("CARBON_AWARE_SDK_API_URL")
+		if err != nil {
+			return nil, err
+		}
+		return NewCarbonAwareSDKFetcher(apiURL, nil)
+	case PrometheusProvider:
+		return newPrometheusFetcherFromEnv()
+	default:
+		return NewGridIntensityFetcher(providerName, cacheOptions)
+	}
+}
+
+// CacheOptions configures the carbon intensity cache of the
+// GridIntensityFetcher.
+type CacheOptions struct {
+	// Store persists the cache across restarts. Optional.
+	Store CacheStore
+	// MinTTL and MaxTTL bound the TTL of cached readings which is based on
+	// their ValidTo.
+	MinTTL time.Duration
+	MaxTTL time.Duration
+	// NegativeTTL is how long invalid readings and unknown locations are
+	// cached.
+	NegativeTTL time.Duration
+	// PersistInterval is how often changes to the cache are saved to the
+	// store.
+	PersistInterval time.Duration
+}
+
 type GridIntensityFetcher struct {
-	cache        *ttlcache.Cache[string, CarbonIntensity]
-	provider     gridprovider.Interface
-	providerName string
+	cache         *ttlcache.Cache[string, CarbonIntensity]
+	negativeCache *ttlcache.Cache[string, negativeCacheEntry]
+	provider      gridprovider.Interface
+	providerName  string
+	store         CacheStore
+	// warmed is closed once Start has loaded the persisted cache. Fetches
+	// wait for it so reconciles use the persisted readings. It is nil when
+	// there is no store.
+	warmed chan struct{}
+	// dirty is set when the cache has changed since it was last persisted.
+	dirty atomic.Bool
+
+	minTTL      time.Duration
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+	// persistInterval is how often the cache is persisted if it changed.
+	persistInterval time.Duration
 }
 
-func NewGridIntensityFetcher(providerName string) (*GridIntensityFetcher, error) {
+// negativeCacheEntry is either an invalid reading or the error for an
+// unknown location.
+type negativeCacheEntry struct {
+	carbonIntensity CarbonIntensity
+	err             error
+}
+
+func NewGridIntensityFetcher(providerName string, cacheOptions CacheOptions) (*GridIntensityFetcher, error) {
 	var provider gridprovider.Interface
 
 	switch providerName {
@@ -78,27 +170,82 @@ func NewGridIntensityFetcher(providerName string) (*GridIntensityFetcher, error)
 		return nil, fmt.Errorf("provider name %s not supported", providerName)
 	}
 
-	return &GridIntensityFetcher{
-		cache:        ttlcache.New[string, CarbonIntensity](ttlcache.WithDisableTouchOnHit[string, CarbonIntensity]()),
-		provider:     provider,
-		providerName: providerName,
-	}, nil
+	g := &GridIntensityFetcher{
+		cache:           ttlcache.New[string, CarbonIntensity](ttlcache.WithDisableTouchOnHit[string, CarbonIntensity]()),
+		negativeCache:   ttlcache.New[string, negativeCacheEntry](ttlcache.WithDisableTouchOnHit[string, negativeCacheEntry]()),
+		provider:        provider,
+		providerName:    providerName,
+		store:           cacheOptions.Store,
+		minTTL:          defaultDuration(cacheOptions.MinTTL, defaultCacheMinTTL),
+		maxTTL:          defaultDuration(cacheOptions.MaxTTL, defaultCacheMaxTTL),
+		negativeTTL:     defaultDuration(cacheOptions.NegativeTTL, defaultCacheNegativeTTL),
+		persistInterval: defaultDuration(cacheOptions.PersistInterval, defaultCachePersistInterval),
+	}
+	if g.minTTL > g.maxTTL {
+		return nil, fmt.Errorf("cache min ttl %s must not be greater than max ttl %s", g.minTTL, g.maxTTL)
+	}
+	if g.store != nil {
+		g.warmed = make(chan struct{})
+	}
+
+	g.cache.OnEviction(func(ctx context.Context, reason ttlcache.EvictionReason, item *ttlcache.Item[string, CarbonIntensity]) {
+		CacheEvictionsTotal.WithLabelValues(providerName, "positive", evictionReason(reason)).Inc()
+	})
+	g.negativeCache.OnEviction(func(ctx context.Context, reason ttlcache.EvictionReason, item *ttlcache.Item[string, negativeCacheEntry]) {
+		CacheEvictionsTotal.WithLabelValues(providerName, "negative", evictionReason(reason)).Inc()
+	})
+
+	return g, nil
 }
 
-func (g *GridIntensityFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+func (g *GridIntensityFetcher) Fetch(ctx context.Context, clusterName, location string) (_ ClusterCarbonIntensity, err error) {
+	ctx, span := startFetchSpan(ctx, "GridIntensityFetcher.Fetch", clusterName, location, g.providerName)
+	defer func() { endSpan(span, err) }()
+
+	if g.warmed != nil {
+		select {
+		case <-g.warmed:
+		case <-ctx.Done():
+			return ClusterCarbonIntensity{}, ctx.Err()
+		}
+	}
+
 	item := g.cache.Get(location)
-	if item != nil && !item.IsExpired() {
+	if item != nil && !item.IsExpired() && !isWithoutCache(ctx) {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		CacheHitsTotal.WithLabelValues(g.providerName, "positive").Inc()
 		carbonIntensity := item.Value()
 		return ClusterCarbonIntensity{ClusterName: clusterName, CarbonIntensity: carbonIntensity}, nil
 	}
 
+	negativeItem := g.negativeCache.Get(location)
+	if negativeItem != nil && !negativeItem.IsExpired() && !isWithoutCache(ctx) {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		CacheHitsTotal.WithLabelValues(g.providerName, "negative").Inc()
+		entry := negativeItem.Value()
+		if entry.err != nil {
+			return ClusterCarbonIntensity{}, entry.err
+		}
+		return ClusterCarbonIntensity{ClusterName: clusterName, CarbonIntensity: entry.carbonIntensity}, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	CacheMissesTotal.WithLabelValues(g.providerName).Inc()
+
 	carbonIntensity, err := g.fetch(ctx, location)
+	if errors.Is(err, ErrUnknownLocation) {
+		g.negativeCache.Set(location, negativeCacheEntry{err: err}, g.negativeTTL)
+	}
 	if err != nil {
 		return ClusterCarbonIntensity{}, err
 	}
 
-	ttl := time.Until(carbonIntensity.ValidTo)
-	g.cache.Set(location, carbonIntensity, ttl)
+	if carbonIntensity.IsValid {
+		g.cache.Set(location, carbonIntensity, g.ttl(carbonIntensity.ValidTo))
+		g.dirty.Store(true)
+	} else {
+		g.negativeCache.Set(location, negativeCacheEntry{carbonIntensity: carbonIntensity}, g.negativeTTL)
+	}
 
 	return ClusterCarbonIntensity{
 		CarbonIntensity: carbonIntensity,
@@ -110,17 +257,141 @@ func (g *GridIntensityFetcher) Provider() string {
 	return g.providerName
 }
 
+// IsCached returns whether there is an unexpired cache entry for the location.
+func (g *GridIntensityFetcher) IsCached(location string) bool {
+	if item := g.cache.Get(location); item != nil && !item.IsExpired() {
+		return true
+	}
+	if item := g.negativeCache.Get(location); item != nil && !item.IsExpired() {
+		return true
+	}
+
+	return false
+}
+
+// Start loads the persisted cache, then removes expired cache entries and
+// persists the cache when it has changed until the context is cancelled.
+// Persisting in the background keeps API calls out of the fetch path.
+func (g *GridIntensityFetcher) Start(ctx context.Context) error {
+	if g.warmed != nil {
+		warmCtx, cancel := context.WithTimeout(ctx, cacheWarmTimeout)
+		g.warmCache(warmCtx)
+		cancel()
+		close(g.warmed)
+	}
+
+	go g.cache.Start()
+	go g.negativeCache.Start()
+	defer g.cache.Stop()
+	defer g.negativeCache.Stop()
+
+	if g.store == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	ticker := time.NewTicker(g.persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.persistCache(ctx)
+		case <-ctx.Done():
+			// Save the latest readings so they are available after a restart.
+			persistCtx, cancel := context.WithTimeout(log.IntoContext(context.Background(), log.FromContext(ctx)), cachePersistShutdownTimeout)
+			g.persistCache(persistCtx)
+			cancel()
+			return nil
+		}
+	}
+}
+
+// ttl returns the time until validTo bounded by the min and max TTL.
+func (g *GridIntensityFetcher) ttl(validTo time.Time) time.Duration {
+	ttl := time.Until(validTo)
+	if ttl < g.minTTL {
+		return g.minTTL
+	} else if ttl > g.maxTTL {
+		return g.maxTTL
+	}
+
+	return ttl
+}
+
+// warmCache loads the persisted cache. Entries keep the same TTL based on
+// ValidTo and expired entries are skipped.
+func (g *GridIntensityFetcher) warmCache(ctx context.Context) {
+	if g.store == nil {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	entries, err := g.store.Load(ctx)
+	if err != nil {
+		logger.Error(err, "unable to load carbon intensity cache")
+		return
+	}
+
+	for location, carbonIntensity := range entries {
+		if !time.Now().Before(carbonIntensity.ValidTo) {
+			continue
+		}
+		g.cache.Set(location, carbonIntensity, g.ttl(carbonIntensity.ValidTo))
+	}
+	logger.Info("warmed carbon intensity cache", "entries", g.cache.Len())
+}
+
+// persistCache saves the unexpired cache entries to the store if the cache
+// has changed.
+func (g *GridIntensityFetcher) persistCache(ctx context.Context) {
+	if g.store == nil || !g.dirty.Swap(false) {
+		return
+	}
+
+	entries := map[string]CarbonIntensity{}
+	for location, item := range g.cache.Items() {
+		if !item.IsExpired() {
+			entries[location] = item.Value()
+		}
+	}
+
+	err := g.store.Save(ctx, entries)
+	if err != nil {
+		g.dirty.Store(true)
+		log.FromContext(ctx).Error(err, "unable to persist carbon intensity cache")
+	}
+}
+
 func (g *GridIntensityFetcher) fetch(ctx context.Context, location string) (CarbonIntensity, error) {
 	carbonIntensity, err := g.provider.GetCarbonIntensity(ctx, location)
-	if errors.Is(err, gridprovider.ErrReceivedNon200Status) {
-		return CarbonIntensity{IsValid: false, Location: location}, nil
-	} else if err != nil {
-		return CarbonIntensity{}, nil
+	if err != nil {
+		return CarbonIntensity{}, fmt.Errorf("failed to get carbon intensity for %s: %w", location, classifyProviderError(err))
 	}
 
 	return parseCarbonIntensity(location, carbonIntensity)
 }
 
+func defaultDuration(d, defaultValue time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultValue
+	}
+
+	return d
+}
+
+func evictionReason(reason ttlcache.EvictionReason) string {
+	switch reason {
+	case ttlcache.EvictionReasonExpired:
+		return "expired"
+	case ttlcache.EvictionReasonCapacityReached:
+		return "capacity"
+	default:
+		return "deleted"
+	}
+}
+
 func getEnvVar(varName string) (string, error) {
 	val := os.Getenv(varName)
 	if val == "" {
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	gridprovider "github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// emptyGridProvider has no data for any location except unknown locations
+// which return an error.
+type emptyGridProvider struct {
+	calls int
+}
+
+func (f *emptyGridProvider) GetCarbonIntensity(ctx context.Context, location string) ([]gridprovider.CarbonIntensity, error) {
+	f.calls++
+	if location == "atlantis" {
+		return nil, gridprovider.ErrInvalidLocation
+	}
+	return nil, nil
+}
+
+func TestGridIntensityFetcherNegativeCache(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	provider := &emptyGridProvider{}
+	fetcher := newFakeGridIntensityFetcher(provider)
+	fetcher.negativeTTL = 50 * time.Millisecond
+
+	// Invalid readings are cached.
+	for i := 0; i < 2; i++ {
+		result, err := fetcher.Fetch(ctx, "member1", "DE")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.CarbonIntensity).To(Equal(CarbonIntensity{IsValid: false, Location: "DE"}))
+	}
+	g.Expect(provider.calls).To(Equal(1))
+	g.Expect(fetcher.IsCached("DE")).To(BeTrue())
+
+	// Unknown locations are cached with their error.
+	for i := 0; i < 2; i++ {
+		_, err := fetcher.Fetch(ctx, "member2", "atlantis")
+		g.Expect(errors.Is(err, ErrUnknownLocation)).To(BeTrue(), "unexpected error %v", err)
+	}
+	g.Expect(provider.calls).To(Equal(2))
+
+	// Both are fetched again once the negative TTL has passed.
+	time.Sleep(fetcher.negativeTTL)
+	g.Expect(fetcher.IsCached("DE")).To(BeFalse())
+	_, err := fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = fetcher.Fetch(ctx, "member2", "atlantis")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(provider.calls).To(Equal(4))
+}
+
+func TestGridIntensityFetcherTTL(t *testing.T) {
+	g := NewWithT(t)
+
+	fetcher := newFakeGridIntensityFetcher(&fakeGridProvider{})
+	fetcher.minTTL = time.Minute
+	fetcher.maxTTL = time.Hour
+
+	g.Expect(fetcher.ttl(time.Now().Add(-time.Hour))).To(Equal(time.Minute))
+	g.Expect(fetcher.ttl(time.Now().Add(10 * time.Second))).To(Equal(time.Minute))
+	g.Expect(fetcher.ttl(time.Now().Add(24 * time.Hour))).To(Equal(time.Hour))
+	g.Expect(fetcher.ttl(time.Now().Add(30 * time.Minute))).To(BeNumerically("~", 30*time.Minute, time.Second))
+}
+
+func TestNewGridIntensityFetcherTTLBounds(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Setenv("ELECTRICITY_MAP_API_URL", "http://electricitymap")
+	t.Setenv("ELECTRICITY_MAP_API_TOKEN", "token")
+
+	_, err := NewGridIntensityFetcher(gridprovider.ElectricityMap, CacheOptions{MinTTL: time.Hour, MaxTTL: time.Minute})
+	g.Expect(err).To(HaveOccurred())
+
+	fetcher, err := NewGridIntensityFetcher(gridprovider.ElectricityMap, CacheOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(fetcher.minTTL).To(Equal(defaultCacheMinTTL))
+	g.Expect(fetcher.maxTTL).To(Equal(defaultCacheMaxTTL))
+	g.Expect(fetcher.negativeTTL).To(Equal(defaultCacheNegativeTTL))
+}
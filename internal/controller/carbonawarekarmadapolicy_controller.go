@@ -3,19 +3,31 @@ package controller
 import (
 	"context"
 	"fmt"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
 )
@@ -30,6 +42,42 @@ type CarbonAwareKarmadaPolicyReconciler struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	CarbonIntensityFetcher
+
+	// FetchConcurrency is the maximum number of locations fetched in
+	// parallel for a policy.
+	FetchConcurrency int
+	// FetchTimeout is the timeout for fetching a single location.
+	FetchTimeout time.Duration
+	// MaxConcurrentReconciles is the maximum number of policies reconciled
+	// in parallel.
+	MaxConcurrentReconciles int
+	// Refresher refreshes the locations used by policies before they
+	// expire. Optional.
+	Refresher *Refresher
+	// MinRequeueInterval and MaxRequeueInterval bound when policies are
+	// evaluated again which is based on when their data expires.
+	MinRequeueInterval time.Duration
+	MaxRequeueInterval time.Duration
+	// RequeueJitter is the maximum fraction of the requeue interval added
+	// as jitter.
+	RequeueJitter float64
+	// KarmadaPreflight delays starting the controller until the Karmada
+	// policy CRDs are found. Optional.
+	KarmadaPreflight *KarmadaPreflight
+	// KarmadaCluster is the Karmada API server when it is not the cluster
+	// the operator runs in. The Karmada policies are read, written and
+	// watched through it. Optional.
+	KarmadaCluster cluster.Cluster
+	// ControlPlanes are the clients for policies with a control plane
+	// reference. Optional.
+	ControlPlanes *ControlPlaneClients
+	// WatchNamespaces restricts the operator to these namespaces so it only
+	// needs namespaced RBAC. Cluster propagation policies are not supported
+	// as they are cluster scoped. Optional, if empty all namespaces are
+	// watched.
+	WatchNamespaces []string
+
+	clusterFetcher *clusterFetcher
 }
 
 //+kubebuilder:rbac:groups=carbonaware.rossf7.github.io,resources=carbonawarekarmadapolicies,verbs=get;list;watch;create;update;patch;delete
@@ -38,6 +86,9 @@ type CarbonAwareKarmadaPolicyReconciler struct {
 //+kubebuilder:rbac:groups=policy.karmada.io,resources=clusterpropagationpolicies,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=policy.karmada.io,resources=propagationpolicies,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=carbonaware.rossf7.github.io,resources=karmadacontrolplanes,verbs=get
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=carbonaware.rossf7.github.io,resources=karmadatargetgrants,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,148 +96,452 @@ type CarbonAwareKarmadaPolicyReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.4/pkg/reconcile
 func (r *CarbonAwareKarmadaPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
+	ctx, span := tracer().Start(ctx, "Reconcile", trace.WithAttributes(
+		attribute.String("namespace", req.Namespace),
+		attribute.String("policy", req.Name),
+	))
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	endSpan(span, err)
 
-	err := karmadav1alpha1.Install(r.Scheme)
+	outcome := "success"
 	if err != nil {
-		logger.Error(err, "failed to install crd")
-		return ctrl.Result{RequeueAfter: requeueInterval}, err
+		outcome = "error"
 	}
+	ReconcileDurationSeconds.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+
+	return result, err
+}
+
+func (r *CarbonAwareKarmadaPolicyReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
 
 	carbonAwareKarmadaPolicy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
-	err = r.Get(ctx, req.NamespacedName, carbonAwareKarmadaPolicy)
+	err := r.Get(ctx, req.NamespacedName, carbonAwareKarmadaPolicy)
 	if err != nil && apierrors.IsNotFound(err) {
 		logger.Error(err, "unable to find carbon aware karmada policy")
+		if r.Refresher != nil {
+			r.Refresher.Untrack(req.NamespacedName)
+		}
+		deletePolicyMetrics(req.Namespace, req.Name)
 		return ctrl.Result{RequeueAfter: requeueInterval}, client.IgnoreNotFound(err)
 	} else if err != nil {
 		logger.Error(err, "failed to find carbon aware karmada policy")
-		ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
+		ReconcileErrorsTotal.WithLabelValues(req.Namespace, req.Name).Inc()
 		return ctrl.Result{RequeueAfter: requeueInterval}, err
 	}
 
-	ReconcilesTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
+	ReconcilesTotal.WithLabelValues(req.Namespace, req.Name).Inc()
 
-	clusters := []ClusterCarbonIntensity{}
+	targetAccepted, err := r.targetCondition(ctx, carbonAwareKarmadaPolicy)
+	if err != nil {
+		logger.Error(err, "unable to check karmada target")
+		ReconcileErrorsTotal.WithLabelValues(req.Namespace, req.Name).Inc()
+		return ctrl.Result{RequeueAfter: requeueInterval}, err
+	}
+	if targetAccepted.Status != metav1.ConditionTrue {
+		logger.Info("karmada target not accepted", "reason", targetAccepted.Reason, "message", targetAccepted.Message)
+		meta.SetStatusCondition(&carbonAwareKarmadaPolicy.Status.Conditions, targetAccepted)
+		if err := r.Status().Update(ctx, carbonAwareKarmadaPolicy); err != nil {
+			logger.Error(err, "unable to update carbon aware policy status")
+			ReconcileErrorsTotal.WithLabelValues(req.Namespace, req.Name).Inc()
+			return ctrl.Result{RequeueAfter: requeueInterval}, err
+		}
+		// The policy is reconciled again when its spec or a grant is changed.
+		return ctrl.Result{}, nil
+	}
 
+	fetchCtx, fetchSpan := tracer().Start(ctx, "FetchCarbonIntensity", trace.WithAttributes(
+		attribute.String("provider", r.CarbonIntensityFetcher.Provider()),
+		attribute.Int("clusters", len(carbonAwareKarmadaPolicy.Spec.ClusterLocations)),
+	))
+	clusters, fetchErrors := r.clusterFetcher.fetchClusters(fetchCtx, carbonAwareKarmadaPolicy.Spec.ClusterLocations)
+	fetchSpan.End()
 	for _, loc := range carbonAwareKarmadaPolicy.Spec.ClusterLocations {
-		clusterCarbonIntensity, err := r.CarbonIntensityFetcher.Fetch(ctx, loc.Name, loc.Location)
-		if err != nil {
-			logger.Error(err, "unable to get carbon intensity", "location", loc.Location)
-			ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+		if err, ok := fetchErrors[loc.Name]; ok {
+			reason := clusterStatusReason(err)
+			logger.Error(err, "unable to get carbon intensity", "location", loc.Location, "reason", reason)
+			ProviderErrorsTotal.WithLabelValues(r.CarbonIntensityFetcher.Provider(), string(reason)).Inc()
 		}
-		clusters = append(clusters, clusterCarbonIntensity)
 	}
 
-	sort.Slice(clusters, func(i, j int) bool {
-		return clusters[i].CarbonIntensity.Value < clusters[j].CarbonIntensity.Value
-	})
+	if r.Refresher != nil {
+		r.Refresher.Track(req.NamespacedName, clusters)
+	}
 
-	activeClusters := []string{}
-	clusterStatuses := []carbonawarev1alpha1.ClusterStatus{}
 	desiredClusters := int(*carbonAwareKarmadaPolicy.Spec.DesiredClusters)
+	rankings := RankClusters(clusters, fetchErrors, desiredClusters)
+	activeClusters := ActiveClusters(rankings)
+	clusterStatuses := []carbonawarev1alpha1.ClusterStatus{}
 
-	for _, c := range clusters {
-		var active bool
+	// Series are recreated each reconcile so removed clusters and previous
+	// active values are not left behind.
+	CarbonIntensityMetric.DeletePartialMatch(prometheus.Labels{"namespace": req.Namespace, "policy": req.Name})
 
-		if c.CarbonIntensity.IsValid && len(activeClusters) < desiredClusters {
-			activeClusters = append(activeClusters, c.ClusterName)
-			active = true
-		}
+	for _, c := range rankings {
+		clusterStatuses = append(clusterStatuses, clusterStatus(c))
+		CarbonIntensityMetric.WithLabelValues(req.Namespace, req.Name, c.ClusterName,
+			c.CarbonIntensity.Location,
+			strconv.FormatBool(c.Active)).Set(c.CarbonIntensity.Value)
+	}
 
-		status := carbonawarev1alpha1.ClusterStatus{
-			IsValid:  c.CarbonIntensity.IsValid,
-			Location: c.CarbonIntensity.Location,
-			Name:     c.ClusterName,
-		}
-		if c.CarbonIntensity.IsValid {
-			ci := carbonawarev1alpha1.ClusterCarbonIntensityStatus{
-				Units:     c.CarbonIntensity.Units,
-				ValidFrom: c.CarbonIntensity.ValidFrom.Format(time.RFC3339),
-				ValidTo:   c.CarbonIntensity.ValidTo.Format(time.RFC3339),
-				Value:     fmt.Sprintf("%.2f", c.CarbonIntensity.Value),
-			}
-			status.CarbonIntensity = ci
+	// If every cluster failed the placement is left unchanged rather than
+	// removing all clusters. The errors are still reported in the status.
+	if len(activeClusters) == 0 && len(fetchErrors) > 0 {
+		err = fmt.Errorf("unable to get carbon intensity for any cluster")
+		logger.Error(err, "skipping update of karmada target")
+		ReconcileErrorsTotal.WithLabelValues(req.Namespace, req.Name).Inc()
+
+		carbonAwareKarmadaPolicy.Status.Clusters = clusterStatuses
+		if statusErr := r.Status().Update(ctx, carbonAwareKarmadaPolicy); statusErr != nil {
+			logger.Error(statusErr, "unable to update carbon aware policy status")
 		}
-		clusterStatuses = append(clusterStatuses, status)
-		CarbonIntensityMetric.WithLabelValues(c.ClusterName,
-			c.CarbonIntensity.Location,
-			strconv.FormatBool(active)).Set(c.CarbonIntensity.Value)
+		return ctrl.Result{RequeueAfter: requeueInterval}, err
+	}
+
+	tiers := ClusterTiers(rankings, desiredClusters)
+	if placementMode(carbonAwareKarmadaPolicy) != carbonawarev1alpha1.ClusterAffinitiesPlacementMode {
+		tiers = tiers[:1]
+	}
+	drift, err := r.updateKarmadaTarget(ctx, carbonAwareKarmadaPolicy, tiers)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: requeueInterval}, err
+	}
+
+	ActiveClustersMetric.WithLabelValues(req.Namespace, req.Name).Set(float64(len(activeClusters)))
+	if !sameClusters(carbonAwareKarmadaPolicy.Status.ActiveClusters, activeClusters) {
+		LastDecisionChangeMetric.WithLabelValues(req.Namespace, req.Name).SetToCurrentTime()
 	}
 
+	carbonAwareKarmadaPolicy.Status.ActiveClusters = activeClusters
+	carbonAwareKarmadaPolicy.Status.ClusterTiers = tiers
+	carbonAwareKarmadaPolicy.Status.Clusters = clusterStatuses
+	meta.SetStatusCondition(&carbonAwareKarmadaPolicy.Status.Conditions, targetAccepted)
+	statusCtx, statusSpan := tracer().Start(ctx, "UpdateStatus")
+	err = r.Status().Update(statusCtx, carbonAwareKarmadaPolicy)
+	endSpan(statusSpan, err)
+	if err != nil {
+		logger.Error(err, "unable to update carbon aware policy status")
+		ReconcileErrorsTotal.WithLabelValues(req.Namespace, req.Name).Inc()
+		return ctrl.Result{RequeueAfter: requeueInterval}, err
+	}
+
+	// Drift is only reported once the corrected tiers are persisted so a
+	// failed update is not counted as a correction.
+	if drift != nil {
+		r.reportDrift(carbonAwareKarmadaPolicy, drift, tiers)
+	}
+
+	return ctrl.Result{RequeueAfter: r.requeueAfter(carbonAwareKarmadaPolicy, clusters)}, nil
+}
+
+// placementDrift is a placement that was changed by someone else since the
+// operator last set it.
+type placementDrift struct {
+	target  string
+	current [][]string
+}
+
+// updateKarmadaTarget sets the placement of the propagation policy or
+// cluster propagation policy to the tiers of clusters. The first tier is
+// the active clusters. If the placement drifted it is returned so it can
+// be reported once the status is updated.
+func (r *CarbonAwareKarmadaPolicyReconciler) updateKarmadaTarget(ctx context.Context,
+	policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy, tiers [][]string) (_ *placementDrift, err error) {
+	ctx, span := tracer().Start(ctx, "UpdateKarmadaTarget", trace.WithAttributes(
+		attribute.String("target", string(policy.Spec.KarmadaTarget)),
+		attribute.String("placement_mode", string(placementMode(policy))),
+		attribute.StringSlice("clusters", tiers[0]),
+		attribute.Int("tiers", len(tiers)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	logger := log.FromContext(ctx)
+
+	karmadaClient, err := r.karmadaClient(ctx, policy)
+	if err != nil {
+		logger.Error(err, "unable to get karmada control plane client")
+		ReconcileErrorsTotal.WithLabelValues(policy.Namespace, policy.Name).Inc()
+		return nil, err
+	}
+
+	var drift *placementDrift
 	switch {
-	case strings.Contains(string(carbonAwareKarmadaPolicy.Spec.KarmadaTarget), "clusterpropagationpolicies"):
+	case strings.Contains(string(policy.Spec.KarmadaTarget), "clusterpropagationpolicies"):
 		clusterPropagationPolicy := &karmadav1alpha1.ClusterPropagationPolicy{}
-		err = r.Get(ctx, types.NamespacedName{Name: carbonAwareKarmadaPolicy.Spec.KarmadaTargetRef.Name}, clusterPropagationPolicy)
+		err = karmadaClient.Get(ctx, types.NamespacedName{Name: policy.Spec.KarmadaTargetRef.Name}, clusterPropagationPolicy)
 		if err != nil && apierrors.IsNotFound(err) {
 			logger.Error(err, "unable to find cluster propagation policy")
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			return nil, err
 		} else if err != nil {
 			logger.Error(err, "failed to find cluster propagation policy")
-			ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			ReconcileErrorsTotal.WithLabelValues(policy.Namespace, policy.Name).Inc()
+			return nil, err
 		}
 
-		if clusterPropagationPolicy.Spec.Placement.ClusterAffinity == nil {
-			clusterPropagationPolicy.Spec.Placement.ClusterAffinity = &karmadav1alpha1.ClusterAffinity{
-				ClusterNames: activeClusters,
-			}
-		} else {
-			clusterPropagationPolicy.Spec.Placement.ClusterAffinity.ClusterNames = activeClusters
+		var changed bool
+		drift, changed = setPlacement(policy, clusterPropagationPolicy, &clusterPropagationPolicy.Spec.Placement, tiers)
+		if !changed {
+			return nil, nil
 		}
-		err = r.Update(ctx, clusterPropagationPolicy)
+		err = karmadaClient.Update(ctx, clusterPropagationPolicy)
 		if err != nil {
 			logger.Error(err, "unable to update cluster propagation policy")
-			ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			ReconcileErrorsTotal.WithLabelValues(policy.Namespace, policy.Name).Inc()
+			return nil, err
 		}
-	case strings.Contains(string(carbonAwareKarmadaPolicy.Spec.KarmadaTarget), "propagationpolicies"):
+	case strings.Contains(string(policy.Spec.KarmadaTarget), "propagationpolicies"):
 		propagationPolicy := &karmadav1alpha1.PropagationPolicy{}
-		err = r.Get(ctx, types.NamespacedName{Name: carbonAwareKarmadaPolicy.Spec.KarmadaTargetRef.Name,
-			Namespace: carbonAwareKarmadaPolicy.Spec.KarmadaTargetRef.Namespace}, propagationPolicy)
+		err = karmadaClient.Get(ctx, types.NamespacedName{Name: policy.Spec.KarmadaTargetRef.Name,
+			Namespace: policy.Spec.KarmadaTargetRef.Namespace}, propagationPolicy)
 		if err != nil && apierrors.IsNotFound(err) {
 			logger.Error(err, "unable to find propagation policy")
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			return nil, err
 		} else if err != nil {
 			logger.Error(err, "failed to find propagation policy")
-			ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			ReconcileErrorsTotal.WithLabelValues(policy.Namespace, policy.Name).Inc()
+			return nil, err
 		}
 
-		if propagationPolicy.Spec.Placement.ClusterAffinity == nil {
-			propagationPolicy.Spec.Placement.ClusterAffinity = &karmadav1alpha1.ClusterAffinity{
-				ClusterNames: activeClusters,
-			}
-		} else {
-			propagationPolicy.Spec.Placement.ClusterAffinity.ClusterNames = activeClusters
+		var changed bool
+		drift, changed = setPlacement(policy, propagationPolicy, &propagationPolicy.Spec.Placement, tiers)
+		if !changed {
+			return nil, nil
 		}
-		err = r.Update(ctx, propagationPolicy)
+		err = karmadaClient.Update(ctx, propagationPolicy)
 		if err != nil {
 			logger.Error(err, "unable to update propagation policy")
-			ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			ReconcileErrorsTotal.WithLabelValues(policy.Namespace, policy.Name).Inc()
+			return nil, err
 		}
 	default:
-		err := fmt.Errorf("unsupported karmada target %s", carbonAwareKarmadaPolicy.Spec.KarmadaTarget)
+		err = fmt.Errorf("unsupported karmada target %s", policy.Spec.KarmadaTarget)
 		logger.Error(err, "unable to update karmada target")
-		return ctrl.Result{RequeueAfter: requeueInterval}, err
+		return nil, err
 	}
 
-	carbonAwareKarmadaPolicy.Status.ActiveClusters = activeClusters
-	carbonAwareKarmadaPolicy.Status.Clusters = clusterStatuses
-	err = r.Status().Update(ctx, carbonAwareKarmadaPolicy)
-	if err != nil {
-		logger.Error(err, "unable to update carbon aware policy status")
-		ReconcileErrorsTotal.WithLabelValues(carbonAwareKarmadaPolicy.Name).Inc()
-		return ctrl.Result{RequeueAfter: requeueInterval}, err
+	return drift, nil
+}
+
+// setPlacement sets the placement to the tiers of clusters. It returns
+// false if they are already set. If the tiers in the placement are not the
+// ones last set by the operator they were changed by someone else and the
+// drift is returned.
+func setPlacement(policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy,
+	target client.Object, placement *karmadav1alpha1.Placement, tiers [][]string) (*placementDrift, bool) {
+	var desired karmadav1alpha1.Placement
+	switch placementMode(policy) {
+	case carbonawarev1alpha1.ClusterAffinitiesPlacementMode:
+		for i, tier := range tiers {
+			desired.ClusterAffinities = append(desired.ClusterAffinities, karmadav1alpha1.ClusterAffinityTerm{
+				AffinityName:    fmt.Sprintf("tier-%d", i+1),
+				ClusterAffinity: karmadav1alpha1.ClusterAffinity{ClusterNames: tier},
+			})
+		}
+	default:
+		desired.ClusterAffinity = &karmadav1alpha1.ClusterAffinity{ClusterNames: tiers[0]}
+	}
+	if samePlacement(*placement, desired) {
+		return nil, false
+	}
+
+	var drift *placementDrift
+	if current := placementTiers(*placement); placementDrifted(policy.Status, current) {
+		drift = &placementDrift{target: target.GetName(), current: current}
+	}
+
+	// Karmada does not allow the cluster affinity and cluster affinities to
+	// both be set so the other one is cleared.
+	switch placementMode(policy) {
+	case carbonawarev1alpha1.ClusterAffinitiesPlacementMode:
+		placement.ClusterAffinity = nil
+		placement.ClusterAffinities = desired.ClusterAffinities
+	default:
+		if placement.ClusterAffinity == nil {
+			placement.ClusterAffinity = &karmadav1alpha1.ClusterAffinity{}
+		}
+		placement.ClusterAffinity.ClusterNames = tiers[0]
+		placement.ClusterAffinities = nil
+	}
+
+	return drift, true
+}
+
+// reportDrift counts the drift correction and records an event for it.
+func (r *CarbonAwareKarmadaPolicyReconciler) reportDrift(policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy,
+	drift *placementDrift, tiers [][]string) {
+	DriftCorrectionsTotal.WithLabelValues(policy.Namespace, policy.Name).Inc()
+	if r.Recorder != nil {
+		r.Recorder.Eventf(policy, corev1.EventTypeWarning, "DriftCorrected",
+			"Cluster affinity of %s %s was changed to %v, setting it to %v",
+			policy.Spec.KarmadaTarget, drift.target, drift.current, tiers)
+	}
+}
+
+func placementMode(policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy) carbonawarev1alpha1.PlacementMode {
+	if policy.Spec.PlacementMode == "" {
+		return carbonawarev1alpha1.ClusterAffinityPlacementMode
+	}
+	return policy.Spec.PlacementMode
+}
+
+// samePlacement returns whether the placement already has the cluster
+// names of the desired placement. The tiers must be in the same order.
+func samePlacement(current, desired karmadav1alpha1.Placement) bool {
+	if desired.ClusterAffinity != nil {
+		return current.ClusterAffinity != nil && len(current.ClusterAffinities) == 0 &&
+			sameClusters(current.ClusterAffinity.ClusterNames, desired.ClusterAffinity.ClusterNames)
+	}
+
+	if current.ClusterAffinity != nil || len(current.ClusterAffinities) != len(desired.ClusterAffinities) {
+		return false
+	}
+	for i := range desired.ClusterAffinities {
+		if current.ClusterAffinities[i].AffinityName != desired.ClusterAffinities[i].AffinityName ||
+			!sameClusters(current.ClusterAffinities[i].ClusterNames, desired.ClusterAffinities[i].ClusterNames) {
+			return false
+		}
 	}
 
-	return ctrl.Result{RequeueAfter: requeueInterval}, nil
+	return true
+}
+
+// placementTiers returns the cluster names of the cluster affinity or of
+// each cluster affinity group.
+func placementTiers(placement karmadav1alpha1.Placement) [][]string {
+	if placement.ClusterAffinity != nil {
+		return [][]string{placement.ClusterAffinity.ClusterNames}
+	}
+
+	tiers := make([][]string, 0, len(placement.ClusterAffinities))
+	for _, term := range placement.ClusterAffinities {
+		tiers = append(tiers, term.ClusterNames)
+	}
+	return tiers
+}
+
+// placementDrifted returns whether the tiers of the placement are not the
+// ones last set by the operator. Policies evaluated before the tiers were
+// recorded only have their active clusters compared. Policies that have
+// never been evaluated have not drifted.
+func placementDrifted(status carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus, current [][]string) bool {
+	if status.ClusterTiers == nil {
+		if status.ActiveClusters == nil {
+			return false
+		}
+		active := []string{}
+		if len(current) > 0 {
+			active = current[0]
+		}
+		return !sameClusters(active, status.ActiveClusters)
+	}
+
+	if len(current) != len(status.ClusterTiers) {
+		return true
+	}
+	for i := range current {
+		if !sameClusters(current[i], status.ClusterTiers[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameClusters returns whether both lists contain the same clusters in any
+// order.
+func sameClusters(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	names := map[string]struct{}{}
+	for _, name := range a {
+		names[name] = struct{}{}
+	}
+	for _, name := range b {
+		if _, ok := names[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// karmadaClient returns the client for the Karmada control plane of the
+// policy.
+func (r *CarbonAwareKarmadaPolicyReconciler) karmadaClient(ctx context.Context,
+	policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy) (client.Client, error) {
+	if ref := policy.Spec.ControlPlaneRef; ref != nil {
+		if r.ControlPlanes == nil {
+			return nil, fmt.Errorf("karmada control planes are not enabled")
+		}
+		return r.ControlPlanes.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: policy.Namespace})
+	}
+
+	if r.KarmadaCluster != nil {
+		return r.KarmadaCluster.GetClient(), nil
+	}
+	return r.Client, nil
+}
+
+// karmadaCache returns the cache used to watch the Karmada policies.
+func (r *CarbonAwareKarmadaPolicyReconciler) karmadaCache(mgr ctrl.Manager) cache.Cache {
+	if r.KarmadaCluster != nil {
+		return r.KarmadaCluster.GetCache()
+	}
+	return mgr.GetCache()
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *CarbonAwareKarmadaPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	r.clusterFetcher = newClusterFetcher(r.CarbonIntensityFetcher, r.FetchConcurrency, r.FetchTimeout)
+
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{},
+		karmadaTargetIndex, indexKarmadaTarget)
+	if err != nil {
+		return err
+	}
+
+	if r.KarmadaPreflight == nil {
+		return r.setupController(mgr)
+	}
+
+	// The controller watches the Karmada policies so it can only be started
+	// once their CRDs exist.
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.KarmadaPreflight.Ready():
+		}
+
+		return r.setupController(mgr)
+	}))
+}
+
+func (r *CarbonAwareKarmadaPolicyReconciler) setupController(mgr ctrl.Manager) error {
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{}
+	clusterPropagationPolicy := &karmadav1alpha1.ClusterPropagationPolicy{}
+
+	// Changes to the cluster affinity of the Karmada policies are corrected
+	// straight away rather than on the next requeue.
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}).
-		Complete(r)
+		WatchesRawSource(source.Kind(r.karmadaCache(mgr), propagationPolicy),
+			handler.EnqueueRequestsFromMapFunc(r.policiesForTarget(carbonawarev1alpha1.PropagationPolicy)),
+			builder.WithPredicates(clusterAffinityChanged)).
+		Watches(&carbonawarev1alpha1.KarmadaTargetGrant{}, handler.EnqueueRequestsFromMapFunc(r.policiesForGrant)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+	// Cluster propagation policies are cluster scoped so they cannot be
+	// watched with namespaced RBAC.
+	if len(r.WatchNamespaces) == 0 {
+		b = b.WatchesRawSource(source.Kind(r.karmadaCache(mgr), clusterPropagationPolicy),
+			handler.EnqueueRequestsFromMapFunc(r.policiesForTarget(carbonawarev1alpha1.ClusterPropagationPolicy)),
+			builder.WithPredicates(clusterAffinityChanged))
+	}
+	if r.Refresher != nil {
+		b = b.WatchesRawSource(&source.Channel{Source: r.Refresher.Events()}, &handler.EnqueueRequestForObject{})
+	}
+
+	return b.Complete(r)
 }
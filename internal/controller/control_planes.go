@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+// ControlPlaneClients builds and caches a client for each Karmada control
+// plane. A client is rebuilt when its control plane or kubeconfig secret
+// changes.
+type ControlPlaneClients struct {
+	reader    client.Reader
+	scheme    *runtime.Scheme
+	newClient func(*rest.Config, client.Options) (client.Client, error)
+
+	mu      sync.Mutex
+	clients map[types.NamespacedName]controlPlaneClient
+}
+
+type controlPlaneClient struct {
+	client client.Client
+	// version is the resource versions of the control plane and secret
+	// the client was built from.
+	version string
+}
+
+// NewControlPlaneClients returns a cache of control plane clients. The
+// reader is used to get the control planes and their secrets and should
+// not be cached so the operator does not need to watch secrets.
+func NewControlPlaneClients(reader client.Reader, scheme *runtime.Scheme) *ControlPlaneClients {
+	return &ControlPlaneClients{
+		reader:    reader,
+		scheme:    scheme,
+		newClient: client.New,
+		clients:   map[types.NamespacedName]controlPlaneClient{},
+	}
+}
+
+// Get returns the client for the control plane.
+func (c *ControlPlaneClients) Get(ctx context.Context, key types.NamespacedName) (client.Client, error) {
+	controlPlane := &carbonawarev1alpha1.KarmadaControlPlane{}
+	err := c.reader.Get(ctx, key, controlPlane)
+	if apierrors.IsNotFound(err) {
+		c.mu.Lock()
+		delete(c.clients, key)
+		c.mu.Unlock()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get karmada control plane %s: %w", key, err)
+	}
+
+	secret := &corev1.Secret{}
+	secretKey := types.NamespacedName{Name: controlPlane.Spec.KubeconfigSecretRef.Name, Namespace: key.Namespace}
+	err = c.reader.Get(ctx, secretKey, secret)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get kubeconfig secret of karmada control plane %s: %w", key, err)
+	}
+
+	version := controlPlane.ResourceVersion + "/" + secret.ResourceVersion
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.clients[key]; ok && cached.version == version {
+		return cached.client, nil
+	}
+
+	dataKey := controlPlane.Spec.KubeconfigSecretRef.Key
+	if dataKey == "" {
+		dataKey = carbonawarev1alpha1.DefaultKubeconfigSecretKey
+	}
+	kubeconfig, ok := secret.Data[dataKey]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s of karmada control plane %s has no key %q", secretKey, key, dataKey)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig for karmada control plane %s: %w", key, err)
+	}
+	cl, err := c.newClient(config, client.Options{Scheme: c.scheme})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client for karmada control plane %s: %w", key, err)
+	}
+
+	c.clients[key] = controlPlaneClient{client: cl, version: version}
+	return cl, nil
+}
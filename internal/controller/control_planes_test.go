@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: karmada
+  cluster:
+    server: https://karmada-apiserver.karmada-system:5443
+contexts:
+- name: karmada
+  context:
+    cluster: karmada
+    user: admin
+current-context: karmada
+users:
+- name: admin
+  user:
+    token: test
+`
+
+func newTestControlPlane(name string) (*carbonawarev1alpha1.KarmadaControlPlane, *corev1.Secret) {
+	controlPlane := &carbonawarev1alpha1.KarmadaControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: carbonawarev1alpha1.KarmadaControlPlaneSpec{
+			KubeconfigSecretRef: carbonawarev1alpha1.KubeconfigSecretRef{Name: name + "-kubeconfig"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-kubeconfig", Namespace: "default"},
+		Data:       map[string][]byte{"kubeconfig": []byte(testKubeconfig)},
+	}
+	return controlPlane, secret
+}
+
+func TestControlPlaneClients(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	controlPlane, secret := newTestControlPlane("production")
+	hostClient := newTestClient(t, controlPlane, secret)
+
+	hosts := []string{}
+	clients := NewControlPlaneClients(hostClient, hostClient.Scheme())
+	clients.newClient = func(config *rest.Config, opts client.Options) (client.Client, error) {
+		hosts = append(hosts, config.Host)
+		return fake.NewClientBuilder().WithScheme(opts.Scheme).Build(), nil
+	}
+
+	key := client.ObjectKeyFromObject(controlPlane)
+	first, err := clients.Get(ctx, key)
+	g.Expect(err).NotTo(HaveOccurred())
+	second, err := clients.Get(ctx, key)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(second).To(BeIdenticalTo(first))
+	g.Expect(hosts).To(Equal([]string{"https://karmada-apiserver.karmada-system:5443"}))
+
+	// The client is rebuilt when the kubeconfig changes.
+	secret.Data["kubeconfig"] = []byte(testKubeconfig + "\n")
+	g.Expect(hostClient.Update(ctx, secret)).To(Succeed())
+	third, err := clients.Get(ctx, key)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(third).NotTo(BeIdenticalTo(first))
+	g.Expect(hosts).To(HaveLen(2))
+
+	controlPlane.Spec.KubeconfigSecretRef.Key = "config"
+	g.Expect(hostClient.Update(ctx, controlPlane)).To(Succeed())
+	_, err = clients.Get(ctx, key)
+	g.Expect(err).To(MatchError(ContainSubstring(`has no key "config"`)))
+
+	_, err = clients.Get(ctx, types.NamespacedName{Name: "staging", Namespace: "default"})
+	g.Expect(err).To(MatchError(ContainSubstring("unable to get karmada control plane default/staging")))
+}
+
+func TestReconcileWithControlPlane(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	controlPlane, secret := newTestControlPlane("production")
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Spec.ControlPlaneRef = &carbonawarev1alpha1.ControlPlaneRef{Name: "production"}
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+	}
+	hostClient := newTestClient(t, controlPlane, secret, policy)
+	karmadaClient := newTestClient(t, propagationPolicy)
+
+	clients := NewControlPlaneClients(hostClient, hostClient.Scheme())
+	clients.newClient = func(*rest.Config, client.Options) (client.Client, error) {
+		return karmadaClient, nil
+	}
+
+	r := newTestReconciler(hostClient)
+	r.ControlPlanes = clients
+	reconcileOnce(t, r, policy)
+
+	updated := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(karmadaClient.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), updated)).To(Succeed())
+	g.Expect(updated.Spec.Placement.ClusterAffinity.ClusterNames).To(Equal([]string{"prd-fr-01"}))
+
+	// The policy is not applied to the default control plane.
+	err := hostClient.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), &karmadav1alpha1.PropagationPolicy{})
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	gridprovider "github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+var (
+	ErrProviderAuth        = errors.New("provider authentication failed")
+	ErrProviderRateLimited = errors.New("provider rate limit exceeded")
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	ErrProviderTransport   = errors.New("provider request failed")
+	ErrUnknownLocation     = errors.New("location is not supported by provider")
+)
+
+// classifyProviderError wraps err with the typed error for its cause so
+// callers can use errors.Is. Errors that cannot be classified are returned
+// unchanged.
+func classifyProviderError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var typedErr error
+	switch {
+	case errors.Is(err, gridprovider.ErrReceived403Forbidden):
+		typedErr = ErrProviderAuth
+	case errors.Is(err, gridprovider.ErrInvalidLocation):
+		typedErr = ErrUnknownLocation
+	case errors.Is(err, gridprovider.ErrReceivedNon200Status):
+		typedErr = statusCodeError(parseStatusCode(err.Error()))
+	case errors.Is(err, context.DeadlineExceeded), isNetError(err):
+		typedErr = ErrProviderTransport
+	}
+	if typedErr == nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", typedErr, err)
+}
+
+// statusCodeError returns the typed error for a non-200 HTTP status code.
+func statusCodeError(code int) error {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrProviderAuth
+	case code == http.StatusTooManyRequests:
+		return ErrProviderRateLimited
+	case code == http.StatusNotFound || code == http.StatusBadRequest:
+		return ErrUnknownLocation
+	case code >= http.StatusInternalServerError:
+		return ErrProviderUnavailable
+	default:
+		return nil
+	}
+}
+
+// parseStatusCode gets the status code from a grid-intensity-go error which
+// starts with the response status e.g. 429 Too Many Requests.
+func parseStatusCode(msg string) int {
+	code, err := strconv.Atoi(strings.SplitN(msg, " ", 2)[0])
+	if err != nil {
+		return 0
+	}
+
+	return code
+}
+
+func isNetError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// clusterStatusReason maps a fetch error to the reason shown in the cluster
+// status.
+func clusterStatusReason(err error) carbonawarev1alpha1.ClusterStatusReason {
+	switch {
+	case errors.Is(err, ErrProviderAuth):
+		return carbonawarev1alpha1.ProviderAuthFailedReason
+	case errors.Is(err, ErrProviderRateLimited):
+		return carbonawarev1alpha1.ProviderRateLimitedReason
+	case errors.Is(err, ErrUnknownLocation):
+		return carbonawarev1alpha1.UnknownLocationReason
+	case errors.Is(err, ErrProviderUnavailable):
+		return carbonawarev1alpha1.ProviderUnavailableReason
+	case errors.Is(err, ErrProviderTransport):
+		return carbonawarev1alpha1.ProviderTransportErrorReason
+	default:
+		return carbonawarev1alpha1.ProviderErrorReason
+	}
+}
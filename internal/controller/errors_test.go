@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	gridprovider "github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+func non200Error(status string) error {
+	return fmt.Errorf("%s - %s: %w", status, "body", gridprovider.ErrReceivedNon200Status)
+}
+
+func TestClassifyProviderError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedErr    error
+		expectedReason carbonawarev1alpha1.ClusterStatusReason
+	}{
+		{
+			name:           "forbidden",
+			err:            gridprovider.ErrReceived403Forbidden,
+			expectedErr:    ErrProviderAuth,
+			expectedReason: carbonawarev1alpha1.ProviderAuthFailedReason,
+		},
+		{
+			name:           "invalid location",
+			err:            gridprovider.ErrInvalidLocation,
+			expectedErr:    ErrUnknownLocation,
+			expectedReason: carbonawarev1alpha1.UnknownLocationReason,
+		},
+		{
+			name:           "401 unauthorized",
+			err:            non200Error("401 Unauthorized"),
+			expectedErr:    ErrProviderAuth,
+			expectedReason: carbonawarev1alpha1.ProviderAuthFailedReason,
+		},
+		{
+			name:           "400 bad request",
+			err:            non200Error("400 Bad Request"),
+			expectedErr:    ErrUnknownLocation,
+			expectedReason: carbonawarev1alpha1.UnknownLocationReason,
+		},
+		{
+			name:           "404 not found",
+			err:            non200Error("404 Not Found"),
+			expectedErr:    ErrUnknownLocation,
+			expectedReason: carbonawarev1alpha1.UnknownLocationReason,
+		},
+		{
+			name:           "429 too many requests",
+			err:            non200Error("429 Too Many Requests"),
+			expectedErr:    ErrProviderRateLimited,
+			expectedReason: carbonawarev1alpha1.ProviderRateLimitedReason,
+		},
+		{
+			name:           "503 service unavailable",
+			err:            non200Error("503 Service Unavailable"),
+			expectedErr:    ErrProviderUnavailable,
+			expectedReason: carbonawarev1alpha1.ProviderUnavailableReason,
+		},
+		{
+			name:           "unclassified status",
+			err:            non200Error("418 I'm a teapot"),
+			expectedReason: carbonawarev1alpha1.ProviderErrorReason,
+		},
+		{
+			name:           "deadline exceeded",
+			err:            fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expectedErr:    ErrProviderTransport,
+			expectedReason: carbonawarev1alpha1.ProviderTransportErrorReason,
+		},
+		{
+			name:           "net error",
+			err:            &url.Error{Op: "Get", URL: "http://provider", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			expectedErr:    ErrProviderTransport,
+			expectedReason: carbonawarev1alpha1.ProviderTransportErrorReason,
+		},
+		{
+			name:           "other error",
+			err:            errors.New("invalid json"),
+			expectedReason: carbonawarev1alpha1.ProviderErrorReason,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := classifyProviderError(tc.err)
+			g.Expect(errors.Is(err, tc.err)).To(BeTrue())
+			if tc.expectedErr != nil {
+				g.Expect(errors.Is(err, tc.expectedErr)).To(BeTrue(), "unexpected error %v", err)
+			} else {
+				g.Expect(err).To(Equal(tc.err))
+			}
+			g.Expect(clusterStatusReason(err)).To(Equal(tc.expectedReason))
+		})
+	}
+
+	NewWithT(t).Expect(classifyProviderError(nil)).To(BeNil())
+}
+
+func TestParseStatusCode(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(parseStatusCode("429 Too Many Requests - slow down: received non-200 status")).To(Equal(429))
+	g.Expect(parseStatusCode("503")).To(Equal(503))
+	g.Expect(parseStatusCode("received non-200 status")).To(Equal(0))
+	g.Expect(parseStatusCode("")).To(Equal(0))
+}
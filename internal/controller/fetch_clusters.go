@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+const (
+	defaultFetchConcurrency = 4
+	defaultFetchTimeout     = 10 * time.Second
+)
+
+// clusterFetcher fetches the carbon intensity for the clusters of a policy
+// concurrently. Concurrent fetches of the same location, including from
+// reconciles of other policies, share a single call to the fetcher.
+type clusterFetcher struct {
+	fetcher     CarbonIntensityFetcher
+	concurrency int
+	timeout     time.Duration
+
+	group singleflight.Group
+}
+
+type clusterFetchResult struct {
+	cluster ClusterCarbonIntensity
+	err     error
+}
+
+func newClusterFetcher(fetcher CarbonIntensityFetcher, concurrency int, timeout time.Duration) *clusterFetcher {
+	if concurrency <= 0 {
+		concurrency = defaultFetchConcurrency
+	}
+	if timeout <= 0 {
+		timeout = defaultFetchTimeout
+	}
+
+	return &clusterFetcher{
+		fetcher:     fetcher,
+		concurrency: concurrency,
+		timeout:     timeout,
+	}
+}
+
+// FetchClusters fetches the carbon intensity of the cluster locations of a
+// policy. Clusters that could not be fetched are returned as invalid and
+// their errors are keyed by cluster name.
+func FetchClusters(ctx context.Context, fetcher CarbonIntensityFetcher, locations []carbonawarev1alpha1.ClusterLocation,
+	concurrency int, timeout time.Duration) ([]ClusterCarbonIntensity, map[string]error) {
+	return newClusterFetcher(fetcher, concurrency, timeout).fetchClusters(ctx, locations)
+}
+
+func (f *clusterFetcher) fetchClusters(ctx context.Context, locations []carbonawarev1alpha1.ClusterLocation) ([]ClusterCarbonIntensity, map[string]error) {
+	clusters := []ClusterCarbonIntensity{}
+	fetchErrors := map[string]error{}
+
+	results := f.fetchAll(ctx, locations)
+	for i, loc := range locations {
+		if results[i].err != nil {
+			fetchErrors[loc.Name] = results[i].err
+
+			// The cluster is included as invalid so the reason is shown in its status.
+			clusters = append(clusters, ClusterCarbonIntensity{
+				CarbonIntensity: CarbonIntensity{IsValid: false, Location: loc.Location},
+				ClusterName:     loc.Name,
+			})
+			continue
+		}
+		clusters = append(clusters, results[i].cluster)
+	}
+
+	return clusters, fetchErrors
+}
+
+// fetchAll returns a result for each cluster location in the same order as
+// the locations.
+func (f *clusterFetcher) fetchAll(ctx context.Context, locations []carbonawarev1alpha1.ClusterLocation) []clusterFetchResult {
+	results := make([]clusterFetchResult, len(locations))
+	sem := make(chan struct{}, f.concurrency)
+
+	var wg sync.WaitGroup
+	for i, loc := range locations {
+		wg.Add(1)
+		go func(i int, loc carbonawarev1alpha1.ClusterLocation) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = clusterFetchResult{err: ctx.Err()}
+				return
+			}
+
+			cluster, err := f.fetch(ctx, loc.Name, loc.Location)
+			results[i] = clusterFetchResult{cluster: cluster, err: err}
+		}(i, loc)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// fetch returns the carbon intensity of a location. Calls for the same
+// location share one call to the fetcher. The shared call uses its own
+// timeout rather than the caller's context so that cancelling one reconcile
+// does not fail the others waiting for it. The caller stops waiting as soon
+// as ctx is done and the shared call runs until it finishes or times out.
+func (f *clusterFetcher) fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	ch := f.group.DoChan(location, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), f.timeout)
+		defer cancel()
+		fetchCtx = log.IntoContext(fetchCtx, log.FromContext(ctx))
+		fetchCtx = trace.ContextWithSpan(fetchCtx, trace.SpanFromContext(ctx))
+
+		result, err := f.fetcher.Fetch(fetchCtx, clusterName, location)
+		timedOut := errors.Is(fetchCtx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded)
+		if err != nil && timedOut && !errors.Is(err, ErrProviderTransport) {
+			err = fmt.Errorf("%w: timed out after %s: %w", ErrProviderTransport, f.timeout, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result.CarbonIntensity, nil
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return ClusterCarbonIntensity{}, res.Err
+		}
+		return ClusterCarbonIntensity{
+			CarbonIntensity: res.Val.(CarbonIntensity),
+			ClusterName:     clusterName,
+		}, nil
+	case <-ctx.Done():
+		return ClusterCarbonIntensity{}, ctx.Err()
+	}
+}
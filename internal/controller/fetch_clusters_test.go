@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+// blockingFetcher records concurrent calls. Fetch waits for release to be
+// closed or for its context to be done.
+type blockingFetcher struct {
+	release chan struct{}
+
+	mu        sync.Mutex
+	calls     map[string]int
+	active    int
+	maxActive int
+}
+
+func newBlockingFetcher() *blockingFetcher {
+	return &blockingFetcher{
+		release: make(chan struct{}),
+		calls:   map[string]int{},
+	}
+}
+
+func (f *blockingFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	f.mu.Lock()
+	f.calls[location]++
+	f.active++
+	if f.active > f.maxActive {
+		f.maxActive = f.active
+	}
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.active--
+		f.mu.Unlock()
+	}()
+
+	select {
+	case <-f.release:
+	case <-ctx.Done():
+		return ClusterCarbonIntensity{}, ctx.Err()
+	}
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: CarbonIntensity{IsValid: true, Location: location, Value: 100},
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (f *blockingFetcher) Provider() string {
+	return "Fake"
+}
+
+func (f *blockingFetcher) stats() (active, maxActive int, calls map[string]int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	calls = map[string]int{}
+	for k, v := range f.calls {
+		calls[k] = v
+	}
+	return f.active, f.maxActive, calls
+}
+
+func TestClusterFetcherConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	fetcher := newBlockingFetcher()
+	locations := []carbonawarev1alpha1.ClusterLocation{
+		{Name: "member1", Location: "DE"},
+		{Name: "member2", Location: "FR"},
+		{Name: "member3", Location: "ES"},
+		{Name: "member4", Location: "IT"},
+		{Name: "member5", Location: "NL"},
+	}
+
+	done := make(chan struct{})
+	var clusters []ClusterCarbonIntensity
+	var fetchErrors map[string]error
+	go func() {
+		defer close(done)
+		clusters, fetchErrors = FetchClusters(context.Background(), fetcher, locations, 2, time.Minute)
+	}()
+
+	g.Eventually(func() int {
+		active, _, _ := fetcher.stats()
+		return active
+	}).Should(Equal(2))
+	g.Consistently(func() int {
+		active, _, _ := fetcher.stats()
+		return active
+	}, 50*time.Millisecond).Should(Equal(2))
+
+	close(fetcher.release)
+	g.Eventually(done).Should(BeClosed())
+
+	_, maxActive, calls := fetcher.stats()
+	g.Expect(maxActive).To(Equal(2))
+	g.Expect(calls).To(HaveLen(5))
+	g.Expect(fetchErrors).To(BeEmpty())
+	g.Expect(clusters).To(HaveLen(5))
+	for i, c := range clusters {
+		g.Expect(c.ClusterName).To(Equal(locations[i].Name))
+		g.Expect(c.CarbonIntensity.Location).To(Equal(locations[i].Location))
+	}
+}
+
+func TestClusterFetcherDeduplicatesLocations(t *testing.T) {
+	g := NewWithT(t)
+
+	fetcher := newBlockingFetcher()
+	f := newClusterFetcher(fetcher, 4, time.Minute)
+
+	// Two policies share a location with each other and within a policy.
+	policyA := []carbonawarev1alpha1.ClusterLocation{
+		{Name: "member1", Location: "DE"},
+		{Name: "member2", Location: "DE"},
+	}
+	policyB := []carbonawarev1alpha1.ClusterLocation{
+		{Name: "member3", Location: "DE"},
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]ClusterCarbonIntensity, 2)
+	for i, locations := range [][]carbonawarev1alpha1.ClusterLocation{policyA, policyB} {
+		wg.Add(1)
+		go func(i int, locations []carbonawarev1alpha1.ClusterLocation) {
+			defer wg.Done()
+			results[i], _ = f.fetchClusters(context.Background(), locations)
+		}(i, locations)
+	}
+
+	g.Eventually(func() int {
+		active, _, _ := fetcher.stats()
+		return active
+	}).Should(Equal(1))
+	// Give the other callers time to join the in-flight call.
+	time.Sleep(50 * time.Millisecond)
+	close(fetcher.release)
+	wg.Wait()
+
+	_, _, calls := fetcher.stats()
+	g.Expect(calls).To(Equal(map[string]int{"DE": 1}))
+	g.Expect(results[0]).To(HaveLen(2))
+	g.Expect(results[0][0].ClusterName).To(Equal("member1"))
+	g.Expect(results[0][1].ClusterName).To(Equal("member2"))
+	g.Expect(results[1]).To(HaveLen(1))
+	g.Expect(results[1][0].ClusterName).To(Equal("member3"))
+	g.Expect(results[1][0].CarbonIntensity.IsValid).To(BeTrue())
+}
+
+func TestClusterFetcherTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	fetcher := newBlockingFetcher()
+	locations := []carbonawarev1alpha1.ClusterLocation{{Name: "member1", Location: "DE"}}
+
+	clusters, fetchErrors := FetchClusters(context.Background(), fetcher, locations, 1, 20*time.Millisecond)
+	g.Expect(errors.Is(fetchErrors["member1"], ErrProviderTransport)).To(BeTrue(), "unexpected error %v", fetchErrors["member1"])
+	g.Expect(clusterStatusReason(fetchErrors["member1"])).To(Equal(carbonawarev1alpha1.ProviderTransportErrorReason))
+	g.Expect(clusters).To(Equal([]ClusterCarbonIntensity{{
+		CarbonIntensity: CarbonIntensity{IsValid: false, Location: "DE"},
+		ClusterName:     "member1",
+	}}))
+}
+
+func TestClusterFetcherCallerCancelled(t *testing.T) {
+	g := NewWithT(t)
+
+	fetcher := newBlockingFetcher()
+	locations := []carbonawarev1alpha1.ClusterLocation{{Name: "member1", Location: "DE"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var fetchErrors map[string]error
+	go func() {
+		defer close(done)
+		_, fetchErrors = FetchClusters(ctx, fetcher, locations, 1, time.Minute)
+	}()
+
+	g.Eventually(func() int {
+		active, _, _ := fetcher.stats()
+		return active
+	}).Should(Equal(1))
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+	g.Expect(fetchErrors["member1"]).To(MatchError(context.Canceled))
+
+	// The shared call keeps running for other callers until its own timeout.
+	active, _, _ := fetcher.stats()
+	g.Expect(active).To(Equal(1))
+	close(fetcher.release)
+	g.Eventually(func() int {
+		active, _, _ := fetcher.stats()
+		return active
+	}).Should(Equal(0))
+}
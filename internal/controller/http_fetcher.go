@@ -0,0 +1,311 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	HTTPProvider = "HTTP"
+
+	defaultHTTPValidity = time.Hour
+	locationPlaceholder = "{location}"
+
+	// defaultHTTPClientTimeout bounds requests to provider APIs when no
+	// client is passed.
+	defaultHTTPClientTimeout = 30 * time.Second
+	// maxResponseBytes limits how much of a provider response is read.
+	maxResponseBytes = 10 << 20
+)
+
+// HTTPFetcherConfig configures a generic HTTP/JSON carbon intensity API.
+type HTTPFetcherConfig struct {
+	// URLTemplate is the API URL with a {location} placeholder.
+	URLTemplate string
+	// AuthHeader and AuthValue set an optional header for authentication
+	// e.g. Authorization: Bearer token.
+	AuthHeader string
+	AuthValue  string
+	// JSONPath expressions to extract fields from the response body. Only
+	// ValuePath is required.
+	ValuePath     string
+	UnitsPath     string
+	ValidFromPath string
+	ValidToPath   string
+}
+
+// HTTPFetcher fetches carbon intensity from any API that returns JSON. The
+// fields are extracted using JSONPath expressions.
+type HTTPFetcher struct {
+	client *http.Client
+	config HTTPFetcherConfig
+	now    func() time.Time
+
+	valuePath     *jsonpath.JSONPath
+	unitsPath     *jsonpath.JSONPath
+	validFromPath *jsonpath.JSONPath
+	validToPath   *jsonpath.JSONPath
+}
+
+func NewHTTPFetcher(config HTTPFetcherConfig, client *http.Client) (*HTTPFetcher, error) {
+	if !strings.Contains(config.URLTemplate, locationPlaceholder) {
+		return nil, fmt.Errorf("url template %s must contain %s", config.URLTemplate, locationPlaceholder)
+	}
+	if client == nil {
+		client = newDefaultHTTPClient()
+	}
+
+	h := &HTTPFetcher{
+		client: client,
+		config: config,
+		now:    time.Now,
+	}
+
+	var err error
+	h.valuePath, err = parseJSONPath("value", config.ValuePath)
+	if err != nil {
+		return nil, err
+	}
+	if h.valuePath == nil {
+		return nil, fmt.Errorf("value json path must be set")
+	}
+	h.unitsPath, err = parseJSONPath("units", config.UnitsPath)
+	if err != nil {
+		return nil, err
+	}
+	h.validFromPath, err = parseJSONPath("validFrom", config.ValidFromPath)
+	if err != nil {
+		return nil, err
+	}
+	h.validToPath, err = parseJSONPath("validTo", config.ValidToPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func newHTTPFetcherFromEnv() (*HTTPFetcher, error) {
+	urlTemplate, err := getEnvVar("HTTP_PROVIDER_URL")
+	if err != nil {
+		return nil, err
+	}
+	valuePath, err := getEnvVar("HTTP_PROVIDER_VALUE_PATH")
+	if err != nil {
+		return nil, err
+	}
+
+	c := HTTPFetcherConfig{
+		URLTemplate:   urlTemplate,
+		AuthHeader:    os.Getenv("HTTP_PROVIDER_AUTH_HEADER"),
+		AuthValue:     os.Getenv("HTTP_PROVIDER_AUTH_VALUE"),
+		ValuePath:     valuePath,
+		UnitsPath:     os.Getenv("HTTP_PROVIDER_UNITS_PATH"),
+		ValidFromPath: os.Getenv("HTTP_PROVIDER_VALID_FROM_PATH"),
+		ValidToPath:   os.Getenv("HTTP_PROVIDER_VALID_TO_PATH"),
+	}
+
+	return NewHTTPFetcher(c, nil)
+}
+
+func (h *HTTPFetcher) Fetch(ctx context.Context, clusterName, location string) (_ ClusterCarbonIntensity, err error) {
+	ctx, span := startFetchSpan(ctx, "HTTPFetcher.Fetch", clusterName, location, HTTPProvider)
+	defer func() { endSpan(span, err) }()
+
+	carbonIntensity, err := h.fetch(ctx, location)
+	if err != nil {
+		return ClusterCarbonIntensity{}, err
+	}
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: carbonIntensity,
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (h *HTTPFetcher) Provider() string {
+	return HTTPProvider
+}
+
+func (h *HTTPFetcher) fetch(ctx context.Context, location string) (CarbonIntensity, error) {
+	reqURL := strings.ReplaceAll(h.config.URLTemplate, locationPlaceholder, url.PathEscape(location))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return CarbonIntensity{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if h.config.AuthHeader != "" {
+		req.Header.Set(h.config.AuthHeader, h.config.AuthValue)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return CarbonIntensity{}, fmt.Errorf("failed to get carbon intensity for %s: %w: %w", location, ErrProviderTransport, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("received status %d", resp.StatusCode)
+		if typedErr := statusCodeError(resp.StatusCode); typedErr != nil {
+			err = fmt.Errorf("%w: %w", typedErr, err)
+		}
+		return CarbonIntensity{}, fmt.Errorf("failed to get carbon intensity for %s: %w", location, err)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return CarbonIntensity{}, err
+	}
+	if len(body) > maxResponseBytes {
+		return CarbonIntensity{}, fmt.Errorf("response for %s is larger than %d bytes", location, maxResponseBytes)
+	}
+
+	var data interface{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return CarbonIntensity{}, fmt.Errorf("failed to decode response for %s: %w", location, err)
+	}
+
+	return h.parse(location, data)
+}
+
+func (h *HTTPFetcher) parse(location string, data interface{}) (CarbonIntensity, error) {
+	value, err := findJSONPath(h.valuePath, data)
+	if err != nil {
+		return CarbonIntensity{}, err
+	}
+	if value == nil {
+		return CarbonIntensity{IsValid: false, Location: location}, nil
+	}
+	floatValue, err := toFloat(value)
+	if err != nil {
+		return CarbonIntensity{}, fmt.Errorf("%w: invalid value for %s: %w", ErrProviderUnavailable, location, err)
+	}
+
+	carbonIntensity := CarbonIntensity{
+		IsValid:   true,
+		Location:  location,
+		Units:     defaultUnits,
+		ValidFrom: h.now(),
+		Value:     floatValue,
+	}
+
+	if units, err := findJSONPath(h.unitsPath, data); err != nil {
+		return CarbonIntensity{}, err
+	} else if units != nil {
+		carbonIntensity.Units = fmt.Sprint(units)
+	}
+	if validFrom, err := findJSONPath(h.validFromPath, data); err != nil {
+		return CarbonIntensity{}, err
+	} else if validFrom != nil {
+		carbonIntensity.ValidFrom, err = toTime(validFrom)
+		if err != nil {
+			return CarbonIntensity{}, fmt.Errorf("invalid validFrom for %s: %w", location, err)
+		}
+	}
+
+	carbonIntensity.ValidTo = carbonIntensity.ValidFrom.Add(defaultHTTPValidity)
+	if validTo, err := findJSONPath(h.validToPath, data); err != nil {
+		return CarbonIntensity{}, err
+	} else if validTo != nil {
+		carbonIntensity.ValidTo, err = toTime(validTo)
+		if err != nil {
+			return CarbonIntensity{}, fmt.Errorf("invalid validTo for %s: %w", location, err)
+		}
+	}
+
+	return carbonIntensity, nil
+}
+
+// newDefaultHTTPClient returns a client with a timeout as http.DefaultClient
+// can wait forever for a provider that does not respond.
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultHTTPClientTimeout}
+}
+
+func parseJSONPath(name, expr string) (*jsonpath.JSONPath, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+
+	j := jsonpath.New(name).AllowMissingKeys(true)
+	err := j.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s json path: %w", name, err)
+	}
+
+	return j, nil
+}
+
+// findJSONPath returns the first result of the expression or nil if the
+// expression is not set or does not match.
+func findJSONPath(j *jsonpath.JSONPath, data interface{}) (interface{}, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	results, err := j.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return nil, nil
+	}
+
+	v := results[0][0]
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		return nil, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// toFloat rejects NaN and infinite values as strconv accepts them in strings.
+func toFloat(v interface{}) (float64, error) {
+	var value float64
+	switch t := v.(type) {
+	case float64:
+		value = t
+	case string:
+		var err error
+		value, err = strconv.ParseFloat(t, 64)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unexpected type %T", v)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("value %v is not finite", value)
+	}
+
+	return value, nil
+}
+
+// toTime parses RFC 3339 strings and unix timestamps in seconds.
+func toTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case float64:
+		return time.Unix(int64(t), 0).UTC(), nil
+	case string:
+		return time.Parse(time.RFC3339, t)
+	default:
+		return time.Time{}, fmt.Errorf("unexpected type %T", v)
+	}
+}
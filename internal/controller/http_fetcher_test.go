@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPFetcher(t *testing.T) {
+	now := time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		config      HTTPFetcherConfig
+		status      int
+		body        string
+		expected    CarbonIntensity
+		expectedErr error
+	}{
+		{
+			name:   "value only",
+			config: HTTPFetcherConfig{ValuePath: ".data.value"},
+			status: http.StatusOK,
+			body:   `{"data":{"value":"301.5"}}`,
+			expected: CarbonIntensity{
+				IsValid:   true,
+				Location:  "DE",
+				Units:     defaultUnits,
+				ValidFrom: now,
+				ValidTo:   now.Add(defaultHTTPValidity),
+				Value:     301.5,
+			},
+		},
+		{
+			name: "all fields",
+			config: HTTPFetcherConfig{
+				ValuePath:     "{.intensity}",
+				UnitsPath:     ".units",
+				ValidFromPath: ".from",
+				ValidToPath:   ".to",
+			},
+			status: http.StatusOK,
+			body:   `{"intensity":190,"units":"kgCO2e/MWh","from":"2023-09-01T10:00:00Z","to":1693566000}`,
+			expected: CarbonIntensity{
+				IsValid:   true,
+				Location:  "DE",
+				Units:     "kgCO2e/MWh",
+				ValidFrom: time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC),
+				ValidTo:   time.Date(2023, 9, 1, 11, 0, 0, 0, time.UTC),
+				Value:     190,
+			},
+		},
+		{
+			name:   "missing value",
+			config: HTTPFetcherConfig{ValuePath: ".value"},
+			status: http.StatusOK,
+			body:   `{"value":null}`,
+			expected: CarbonIntensity{
+				IsValid:  false,
+				Location: "DE",
+			},
+		},
+		{
+			name:        "unknown location",
+			config:      HTTPFetcherConfig{ValuePath: ".value"},
+			status:      http.StatusNotFound,
+			expectedErr: ErrUnknownLocation,
+		},
+		{
+			name:        "NaN value",
+			config:      HTTPFetcherConfig{ValuePath: ".value"},
+			status:      http.StatusOK,
+			body:        `{"value":"NaN"}`,
+			expectedErr: ErrProviderUnavailable,
+		},
+		{
+			name:        "infinite value",
+			config:      HTTPFetcherConfig{ValuePath: ".value"},
+			status:      http.StatusOK,
+			body:        `{"value":"-Inf"}`,
+			expectedErr: ErrProviderUnavailable,
+		},
+		{
+			name:        "rate limited",
+			config:      HTTPFetcherConfig{ValuePath: ".value"},
+			status:      http.StatusTooManyRequests,
+			expectedErr: ErrProviderRateLimited,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/intensity/DE" || r.Header.Get("X-Api-Key") != "secret" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			config := tc.config
+			config.URLTemplate = server.URL + "/intensity/{location}"
+			config.AuthHeader = "X-Api-Key"
+			config.AuthValue = "secret"
+
+			fetcher, err := NewHTTPFetcher(config, server.Client())
+			g.Expect(err).NotTo(HaveOccurred())
+			fetcher.now = func() time.Time { return now }
+
+			result, err := fetcher.Fetch(context.Background(), "member1", "DE")
+			if tc.expectedErr != nil {
+				g.Expect(errors.Is(err, tc.expectedErr)).To(BeTrue(), "unexpected error %v", err)
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.ClusterName).To(Equal("member1"))
+			g.Expect(result.CarbonIntensity).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestHTTPFetcherLimits(t *testing.T) {
+	g := NewWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"value":1,"padding":"%s"}`, strings.Repeat("x", maxResponseBytes))
+	}))
+	defer server.Close()
+
+	fetcher, err := NewHTTPFetcher(HTTPFetcherConfig{
+		URLTemplate: server.URL + "/{location}",
+		ValuePath:   ".value",
+	}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(fetcher.client.Timeout).To(Equal(defaultHTTPClientTimeout))
+
+	_, err = fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).To(MatchError(ContainSubstring("larger than")))
+}
+
+func TestNewHTTPFetcher(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewHTTPFetcher(HTTPFetcherConfig{URLTemplate: "http://example.com", ValuePath: ".value"}, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewHTTPFetcher(HTTPFetcherConfig{URLTemplate: "http://example.com/{location}"}, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewHTTPFetcher(HTTPFetcherConfig{URLTemplate: "http://example.com/{location}", ValuePath: "{.value"}, nil)
+	g.Expect(err).To(HaveOccurred())
+}
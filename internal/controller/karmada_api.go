@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// KarmadaPolicyGroup is the API group of the Karmada policy CRDs.
+	KarmadaPolicyGroup = "policy.karmada.io"
+
+	defaultPreflightInterval = 10 * time.Second
+)
+
+// KarmadaPolicyVersions are the versions of the Karmada policy API the
+// operator supports, newest first. Only versions whose types are compiled
+// into the operator are supported so placements are never decoded with the
+// schema of another version.
+var KarmadaPolicyVersions = []string{karmadav1alpha1.SchemeGroupVersion.Version}
+
+// ErrKarmadaAPINotFound is returned when no supported version of the
+// Karmada policy API is served.
+var ErrKarmadaAPINotFound = errors.New("karmada policy API not found")
+
+// AddKarmadaToScheme registers the Karmada policy types for every
+// supported version.
+func AddKarmadaToScheme(scheme *runtime.Scheme) error {
+	return karmadav1alpha1.Install(scheme)
+}
+
+// DetectKarmadaAPIVersion returns the newest supported version of the
+// Karmada policy API that serves both propagation policies and cluster
+// propagation policies. If the API is only served at versions the operator
+// does not support the error lists them.
+func DetectKarmadaAPIVersion(dc discovery.DiscoveryInterface) (schema.GroupVersion, error) {
+	for _, version := range KarmadaPolicyVersions {
+		gv := schema.GroupVersion{Group: KarmadaPolicyGroup, Version: version}
+		resources, err := dc.ServerResourcesForGroupVersion(gv.String())
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return schema.GroupVersion{}, fmt.Errorf("unable to discover %s: %w", gv, err)
+		}
+
+		found := map[string]bool{}
+		for _, r := range resources.APIResources {
+			found[r.Name] = true
+		}
+		if found["propagationpolicies"] && found["clusterpropagationpolicies"] {
+			return gv, nil
+		}
+	}
+
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return schema.GroupVersion{}, fmt.Errorf("unable to discover API groups: %w", err)
+	}
+	for _, group := range groups.Groups {
+		if group.Name != KarmadaPolicyGroup || len(group.Versions) == 0 {
+			continue
+		}
+		served := make([]string, 0, len(group.Versions))
+		for _, v := range group.Versions {
+			if containsString(KarmadaPolicyVersions, v.Version) {
+				return schema.GroupVersion{}, ErrKarmadaAPINotFound
+			}
+			served = append(served, v.Version)
+		}
+		return schema.GroupVersion{}, fmt.Errorf("%w: served versions %v are not supported, supported versions are %v",
+			ErrKarmadaAPINotFound, served, KarmadaPolicyVersions)
+	}
+
+	return schema.GroupVersion{}, ErrKarmadaAPINotFound
+}
+
+// KarmadaPreflight checks the Karmada policy CRDs are installed. It polls
+// until they are found so the operator can be deployed before Karmada and
+// fails the ready check until then.
+type KarmadaPreflight struct {
+	discovery discovery.DiscoveryInterface
+	interval  time.Duration
+
+	mu    sync.RWMutex
+	err   error
+	ready chan struct{}
+}
+
+func NewKarmadaPreflight(dc discovery.DiscoveryInterface, interval time.Duration) *KarmadaPreflight {
+	if interval <= 0 {
+		interval = defaultPreflightInterval
+	}
+
+	return &KarmadaPreflight{
+		discovery: dc,
+		interval:  interval,
+		err:       ErrKarmadaAPINotFound,
+		ready:     make(chan struct{}),
+	}
+}
+
+// Start polls for the Karmada policy CRDs until they are found.
+func (p *KarmadaPreflight) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("karmada-preflight")
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		version, err := DetectKarmadaAPIVersion(p.discovery)
+		p.mu.Lock()
+		p.err = err
+		p.mu.Unlock()
+
+		if err == nil {
+			logger.Info("found karmada policy API", "version", version.String())
+			close(p.ready)
+			return nil
+		}
+		logger.Info("waiting for karmada policy CRDs", "reason", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false so every replica reports whether the
+// CRDs are installed.
+func (p *KarmadaPreflight) NeedLeaderElection() bool {
+	return false
+}
+
+// Check is a ready check that fails until the CRDs are found.
+func (p *KarmadaPreflight) Check(_ *http.Request) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.err
+}
+
+// Ready is closed when the CRDs are found.
+func (p *KarmadaPreflight) Ready() <-chan struct{} {
+	return p.ready
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func karmadaResources(version string, resources ...string) *metav1.APIResourceList {
+	list := &metav1.APIResourceList{GroupVersion: KarmadaPolicyGroup + "/" + version}
+	for _, r := range resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: r})
+	}
+	return list
+}
+
+func TestDetectKarmadaAPIVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		expected  schema.GroupVersion
+		err       error
+		errMsg    string
+	}{
+		{
+			name: "v1alpha1",
+			resources: []*metav1.APIResourceList{
+				karmadaResources("v1alpha1", "propagationpolicies", "clusterpropagationpolicies", "overridepolicies"),
+			},
+			expected: karmadav1alpha1.SchemeGroupVersion,
+		},
+		{
+			name: "unsupported versions are ignored",
+			resources: []*metav1.APIResourceList{
+				karmadaResources("v1alpha1", "propagationpolicies", "clusterpropagationpolicies"),
+				karmadaResources("v1beta1", "propagationpolicies", "clusterpropagationpolicies"),
+			},
+			expected: karmadav1alpha1.SchemeGroupVersion,
+		},
+		{
+			name: "only unsupported versions",
+			resources: []*metav1.APIResourceList{
+				karmadaResources("v1beta1", "propagationpolicies", "clusterpropagationpolicies"),
+			},
+			err:    ErrKarmadaAPINotFound,
+			errMsg: "served versions [v1beta1] are not supported",
+		},
+		{
+			name: "missing cluster propagation policies",
+			resources: []*metav1.APIResourceList{
+				karmadaResources("v1alpha1", "propagationpolicies"),
+			},
+			err: ErrKarmadaAPINotFound,
+		},
+		{
+			name: "not installed",
+			err:  ErrKarmadaAPINotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+			version, err := DetectKarmadaAPIVersion(dc)
+			if tc.err != nil {
+				g.Expect(err).To(MatchError(tc.err))
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errMsg)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(version).To(Equal(tc.expected))
+		})
+	}
+}
+
+// installingDiscovery serves no resources until the CRDs are installed.
+type installingDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	installed atomic.Bool
+}
+
+func (d *installingDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if !d.installed.Load() {
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, groupVersion)
+	}
+	return d.FakeDiscovery.ServerResourcesForGroupVersion(groupVersion)
+}
+
+func TestKarmadaPreflight(t *testing.T) {
+	g := NewWithT(t)
+
+	dc := &installingDiscovery{FakeDiscovery: &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			karmadaResources("v1alpha1", "propagationpolicies", "clusterpropagationpolicies"),
+		},
+	}}}
+	preflight := NewKarmadaPreflight(dc, 10*time.Millisecond)
+	g.Expect(preflight.Check(nil)).To(MatchError(ErrKarmadaAPINotFound))
+
+	done := make(chan error)
+	go func() { done <- preflight.Start(context.Background()) }()
+
+	g.Consistently(preflight.Ready(), 50*time.Millisecond).ShouldNot(BeClosed())
+	g.Expect(preflight.Check(nil)).To(MatchError(ErrKarmadaAPINotFound))
+
+	dc.installed.Store(true)
+
+	g.Eventually(preflight.Ready()).Should(BeClosed())
+	g.Eventually(done).Should(Receive(BeNil()))
+	g.Expect(preflight.Check(nil)).To(Succeed())
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+const (
+	// karmadaTargetIndex indexes policies by their Karmada target so
+	// changes to the target can be mapped back to the policies.
+	karmadaTargetIndex = ".spec.karmadaTargetRef"
+)
+
+// karmadaTargetKey returns the index key of a Karmada policy. Cluster
+// propagation policies have an empty namespace.
+func karmadaTargetKey(target carbonawarev1alpha1.KarmadaTarget, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", target, namespace, name)
+}
+
+func indexKarmadaTarget(obj client.Object) []string {
+	policy, ok := obj.(*carbonawarev1alpha1.CarbonAwareKarmadaPolicy)
+	if !ok {
+		return nil
+	}
+	// Only the Karmada policies of the default control plane are watched.
+	if policy.Spec.ControlPlaneRef != nil {
+		return nil
+	}
+
+	namespace := policy.Spec.KarmadaTargetRef.Namespace
+	if policy.Spec.KarmadaTarget == carbonawarev1alpha1.ClusterPropagationPolicy {
+		namespace = ""
+	}
+
+	return []string{karmadaTargetKey(policy.Spec.KarmadaTarget, namespace, policy.Spec.KarmadaTargetRef.Name)}
+}
+
+// policiesForTarget returns a map func that enqueues the policies that
+// target the Karmada object.
+func (r *CarbonAwareKarmadaPolicyReconciler) policiesForTarget(target carbonawarev1alpha1.KarmadaTarget) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		policies := &carbonawarev1alpha1.CarbonAwareKarmadaPolicyList{}
+		err := r.List(ctx, policies, client.MatchingFields{
+			karmadaTargetIndex: karmadaTargetKey(target, obj.GetNamespace(), obj.GetName()),
+		})
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to list policies for karmada target", "target", target, "name", obj.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(policies.Items))
+		for _, p := range policies.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+		}
+		return requests
+	}
+}
+
+// clusterAffinityChanged filters updates of Karmada policies to those that
+// change the cluster affinity or cluster affinities.
+var clusterAffinityChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldAffinity, oldAffinities := clusterAffinity(e.ObjectOld)
+		newAffinity, newAffinities := clusterAffinity(e.ObjectNew)
+		return !reflect.DeepEqual(oldAffinity, newAffinity) || !reflect.DeepEqual(oldAffinities, newAffinities)
+	},
+}
+
+func clusterAffinity(obj client.Object) (*karmadav1alpha1.ClusterAffinity, []karmadav1alpha1.ClusterAffinityTerm) {
+	switch o := obj.(type) {
+	case *karmadav1alpha1.PropagationPolicy:
+		return o.Spec.Placement.ClusterAffinity, o.Spec.Placement.ClusterAffinities
+	case *karmadav1alpha1.ClusterPropagationPolicy:
+		return o.Spec.Placement.ClusterAffinity, o.Spec.Placement.ClusterAffinities
+	default:
+		return nil, nil
+	}
+}
+
+// targetCondition returns whether the Karmada target of the policy can be
+// updated. When namespaces are watched cluster propagation policies and
+// targets in other namespaces cannot be read. Targets in other namespaces
+// must be granted by a KarmadaTargetGrant.
+func (r *CarbonAwareKarmadaPolicyReconciler) targetCondition(ctx context.Context,
+	policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy) (metav1.Condition, error) {
+	accepted := metav1.Condition{
+		Type:               carbonawarev1alpha1.TargetAcceptedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             carbonawarev1alpha1.AcceptedReason,
+		Message:            "Karmada target can be updated",
+		ObservedGeneration: policy.Generation,
+	}
+	rejected := func(reason, message string) metav1.Condition {
+		condition := accepted
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reason
+		condition.Message = message
+		return condition
+	}
+
+	// Control planes use their own clients so are not restricted.
+	if len(r.WatchNamespaces) > 0 && policy.Spec.ControlPlaneRef == nil {
+		if policy.Spec.KarmadaTarget == carbonawarev1alpha1.ClusterPropagationPolicy {
+			return rejected(carbonawarev1alpha1.ClusterScopedTargetNotAllowedReason,
+				"ClusterPropagationPolicy targets are not supported when the operator watches namespaces"), nil
+		}
+
+		namespace := policy.Spec.KarmadaTargetRef.Namespace
+		if !containsString(r.WatchNamespaces, namespace) {
+			return rejected(carbonawarev1alpha1.NamespaceNotWatchedReason,
+				fmt.Sprintf("namespace %s of the Karmada target is not watched by the operator", namespace)), nil
+		}
+	}
+
+	permitted, err := carbonawarev1alpha1.TargetPermitted(ctx, r.Client, policy)
+	if err != nil {
+		return metav1.Condition{}, err
+	}
+	if !permitted {
+		return rejected(carbonawarev1alpha1.ReferenceNotPermittedReason,
+			fmt.Sprintf("no KarmadaTargetGrant in namespace %s allows policies in namespace %s to target %s",
+				policy.Spec.KarmadaTargetRef.Namespace, policy.Namespace, policy.Spec.KarmadaTargetRef.Name)), nil
+	}
+
+	return accepted, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// policiesForGrant returns the policies in the namespaces of a
+// KarmadaTargetGrant that target its namespace so they are evaluated again
+// when it changes.
+func (r *CarbonAwareKarmadaPolicyReconciler) policiesForGrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	grant, ok := obj.(*carbonawarev1alpha1.KarmadaTargetGrant)
+	if !ok {
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, from := range grant.Spec.From {
+		policies := &carbonawarev1alpha1.CarbonAwareKarmadaPolicyList{}
+		err := r.List(ctx, policies, client.InNamespace(from.Namespace))
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to list policies for karmada target grant", "grant", grant.Name)
+			continue
+		}
+
+		for _, p := range policies.Items {
+			if p.Spec.KarmadaTargetRef.Namespace == grant.Namespace {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,464 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+func newTestPolicy(name string, target carbonawarev1alpha1.KarmadaTarget, targetName string) *carbonawarev1alpha1.CarbonAwareKarmadaPolicy {
+	desiredClusters := int32(1)
+	return &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: carbonawarev1alpha1.CarbonAwareKarmadaPolicySpec{
+			ClusterLocations: []carbonawarev1alpha1.ClusterLocation{
+				{Name: "prd-de-01", Location: "DE"},
+				{Name: "prd-fr-01", Location: "FR"},
+			},
+			DesiredClusters:  &desiredClusters,
+			KarmadaTarget:    target,
+			KarmadaTargetRef: carbonawarev1alpha1.KarmadaTargetRef{Name: targetName, Namespace: "default"},
+		},
+	}
+}
+
+func newTestClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	NewWithT(t).Expect(carbonawarev1alpha1.AddToScheme(scheme)).To(Succeed())
+	NewWithT(t).Expect(karmadav1alpha1.Install(scheme)).To(Succeed())
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}).
+		WithIndex(&carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}, karmadaTargetIndex, indexKarmadaTarget).
+		Build()
+}
+
+// newTestReconciler returns a reconciler that gets the carbon intensity
+// from fakeGridProvider.
+func newTestReconciler(c client.Client) *CarbonAwareKarmadaPolicyReconciler {
+	fetcher := newFakeGridIntensityFetcher(&fakeGridProvider{})
+	return &CarbonAwareKarmadaPolicyReconciler{
+		Client:                 c,
+		Scheme:                 c.Scheme(),
+		CarbonIntensityFetcher: fetcher,
+		clusterFetcher:         newClusterFetcher(fetcher, 0, 0),
+	}
+}
+
+// reconcileOnce reconciles the policy and fails the test on error.
+func reconcileOnce(t *testing.T, r *CarbonAwareKarmadaPolicyReconciler, policy client.Object) ctrl.Result {
+	t.Helper()
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+	NewWithT(t).Expect(err).NotTo(HaveOccurred())
+	return result
+}
+
+func TestPoliciesForTarget(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTestClient(t,
+		newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation"),
+		newTestPolicy("redis-policy", carbonawarev1alpha1.PropagationPolicy, "redis-propagation"),
+		newTestPolicy("global-policy", carbonawarev1alpha1.ClusterPropagationPolicy, "nginx-propagation"),
+	)
+	r := &CarbonAwareKarmadaPolicyReconciler{Client: c}
+
+	requests := r.policiesForTarget(carbonawarev1alpha1.PropagationPolicy)(context.Background(),
+		&karmadav1alpha1.PropagationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"}})
+	g.Expect(requests).To(Equal([]reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "nginx-policy", Namespace: "default"}},
+	}))
+
+	requests = r.policiesForTarget(carbonawarev1alpha1.ClusterPropagationPolicy)(context.Background(),
+		&karmadav1alpha1.ClusterPropagationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation"}})
+	g.Expect(requests).To(Equal([]reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "global-policy", Namespace: "default"}},
+	}))
+}
+
+func TestReconcileCorrectsDrift(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Status.ActiveClusters = []string{"prd-fr-01"}
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+		Spec: karmadav1alpha1.PropagationSpec{
+			Placement: karmadav1alpha1.Placement{
+				ClusterAffinity: &karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-de-01"}},
+			},
+		},
+	}
+	c := newTestClient(t, policy, propagationPolicy)
+	recorder := record.NewFakeRecorder(10)
+
+	r := newTestReconciler(c)
+	r.Recorder = recorder
+	reconcileOnce(t, r, policy)
+
+	updated := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), updated)).To(Succeed())
+	g.Expect(updated.Spec.Placement.ClusterAffinity.ClusterNames).To(Equal([]string{"prd-fr-01"}))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("DriftCorrected")))
+
+	// When the placement is already correct the target is not updated.
+	reconcileOnce(t, r, policy)
+	unchanged := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), unchanged)).To(Succeed())
+	g.Expect(unchanged.ResourceVersion).To(Equal(updated.ResourceVersion))
+	g.Expect(recorder.Events).NotTo(Receive())
+}
+
+// fakeKarmadaCluster is a Karmada API server with only a client.
+type fakeKarmadaCluster struct {
+	cluster.Cluster
+	client client.Client
+}
+
+func (c *fakeKarmadaCluster) GetClient() client.Client {
+	return c.client
+}
+
+func TestReconcileWithKarmadaCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+	}
+	hostClient := newTestClient(t, policy)
+	karmadaClient := newTestClient(t, propagationPolicy)
+
+	r := newTestReconciler(hostClient)
+	r.KarmadaCluster = &fakeKarmadaCluster{client: karmadaClient}
+	reconcileOnce(t, r, policy)
+
+	updated := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(karmadaClient.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), updated)).To(Succeed())
+	g.Expect(updated.Spec.Placement.ClusterAffinity.ClusterNames).To(Equal([]string{"prd-fr-01"}))
+
+	updatedPolicy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+	g.Expect(hostClient.Get(ctx, client.ObjectKeyFromObject(policy), updatedPolicy)).To(Succeed())
+	g.Expect(updatedPolicy.Status.ActiveClusters).To(Equal([]string{"prd-fr-01"}))
+	g.Expect(meta.IsStatusConditionTrue(updatedPolicy.Status.Conditions, carbonawarev1alpha1.TargetAcceptedCondition)).To(BeTrue())
+}
+
+func newCrossNamespacePolicy() *carbonawarev1alpha1.CarbonAwareKarmadaPolicy {
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Namespace = "team-a"
+	policy.Spec.KarmadaTargetRef.Namespace = "team-b"
+	return policy
+}
+
+func newTestGrant(from, to string) *carbonawarev1alpha1.KarmadaTargetGrant {
+	return &carbonawarev1alpha1.KarmadaTargetGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-" + from, Namespace: "team-b"},
+		Spec: carbonawarev1alpha1.KarmadaTargetGrantSpec{
+			From: []carbonawarev1alpha1.KarmadaTargetGrantFrom{{Namespace: from}},
+			To:   []carbonawarev1alpha1.KarmadaTargetGrantTo{{Name: to}},
+		},
+	}
+}
+
+func TestTargetCondition(t *testing.T) {
+	tests := []struct {
+		name            string
+		watchNamespaces []string
+		grants          []client.Object
+		policy          *carbonawarev1alpha1.CarbonAwareKarmadaPolicy
+		status          metav1.ConditionStatus
+		reason          string
+	}{
+		{
+			name:   "all namespaces",
+			policy: newTestPolicy("global-policy", carbonawarev1alpha1.ClusterPropagationPolicy, "nginx-propagation"),
+			status: metav1.ConditionTrue,
+			reason: carbonawarev1alpha1.AcceptedReason,
+		},
+		{
+			name:            "watched namespace",
+			watchNamespaces: []string{"tenant-a", "default"},
+			policy:          newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation"),
+			status:          metav1.ConditionTrue,
+			reason:          carbonawarev1alpha1.AcceptedReason,
+		},
+		{
+			name:            "cluster propagation policy",
+			watchNamespaces: []string{"default"},
+			policy:          newTestPolicy("global-policy", carbonawarev1alpha1.ClusterPropagationPolicy, "nginx-propagation"),
+			status:          metav1.ConditionFalse,
+			reason:          carbonawarev1alpha1.ClusterScopedTargetNotAllowedReason,
+		},
+		{
+			name:            "namespace not watched",
+			watchNamespaces: []string{"tenant-a"},
+			policy:          newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation"),
+			status:          metav1.ConditionFalse,
+			reason:          carbonawarev1alpha1.NamespaceNotWatchedReason,
+		},
+		{
+			name:   "cross namespace without grant",
+			grants: []client.Object{newTestGrant("team-c", "")},
+			policy: newCrossNamespacePolicy(),
+			status: metav1.ConditionFalse,
+			reason: carbonawarev1alpha1.ReferenceNotPermittedReason,
+		},
+		{
+			name:   "cross namespace grant for another policy",
+			grants: []client.Object{newTestGrant("team-a", "redis-propagation")},
+			policy: newCrossNamespacePolicy(),
+			status: metav1.ConditionFalse,
+			reason: carbonawarev1alpha1.ReferenceNotPermittedReason,
+		},
+		{
+			name:   "cross namespace grant for all policies",
+			grants: []client.Object{newTestGrant("team-a", "")},
+			policy: newCrossNamespacePolicy(),
+			status: metav1.ConditionTrue,
+			reason: carbonawarev1alpha1.AcceptedReason,
+		},
+		{
+			name:   "cross namespace grant for the policy",
+			grants: []client.Object{newTestGrant("team-a", "nginx-propagation")},
+			policy: newCrossNamespacePolicy(),
+			status: metav1.ConditionTrue,
+			reason: carbonawarev1alpha1.AcceptedReason,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &CarbonAwareKarmadaPolicyReconciler{
+				Client:          newTestClient(t, tc.grants...),
+				WatchNamespaces: tc.watchNamespaces,
+			}
+			condition, err := r.targetCondition(context.Background(), tc.policy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condition.Type).To(Equal(carbonawarev1alpha1.TargetAcceptedCondition))
+			g.Expect(condition.Status).To(Equal(tc.status))
+			g.Expect(condition.Reason).To(Equal(tc.reason))
+		})
+	}
+}
+
+func TestReconcileRejectsClusterPropagationPolicyWhenNamespaced(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	policy := newTestPolicy("global-policy", carbonawarev1alpha1.ClusterPropagationPolicy, "nginx-propagation")
+	clusterPropagationPolicy := &karmadav1alpha1.ClusterPropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation"},
+	}
+	c := newTestClient(t, policy, clusterPropagationPolicy)
+
+	r := newTestReconciler(c)
+	r.WatchNamespaces = []string{"default"}
+	g.Expect(reconcileOnce(t, r, policy)).To(Equal(ctrl.Result{}))
+
+	updated := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(policy), updated)).To(Succeed())
+	condition := meta.FindStatusCondition(updated.Status.Conditions, carbonawarev1alpha1.TargetAcceptedCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(carbonawarev1alpha1.ClusterScopedTargetNotAllowedReason))
+
+	unchanged := &karmadav1alpha1.ClusterPropagationPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(clusterPropagationPolicy), unchanged)).To(Succeed())
+	g.Expect(unchanged.Spec.Placement.ClusterAffinity).To(BeNil())
+}
+
+func TestPoliciesForGrant(t *testing.T) {
+	g := NewWithT(t)
+
+	sameNamespace := newTestPolicy("redis-policy", carbonawarev1alpha1.PropagationPolicy, "redis-propagation")
+	sameNamespace.Namespace = "team-a"
+	c := newTestClient(t, newCrossNamespacePolicy(), sameNamespace)
+	r := &CarbonAwareKarmadaPolicyReconciler{Client: c}
+
+	requests := r.policiesForGrant(context.Background(), newTestGrant("team-a", ""))
+	g.Expect(requests).To(Equal([]reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "nginx-policy", Namespace: "team-a"}},
+	}))
+}
+
+func TestReconcileClusterAffinities(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Spec.PlacementMode = carbonawarev1alpha1.ClusterAffinitiesPlacementMode
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+		Spec: karmadav1alpha1.PropagationSpec{
+			Placement: karmadav1alpha1.Placement{
+				ClusterAffinity: &karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-de-01"}},
+			},
+		},
+	}
+	c := newTestClient(t, policy, propagationPolicy)
+	r := newTestReconciler(c)
+	reconcileOnce(t, r, policy)
+
+	updated := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), updated)).To(Succeed())
+	g.Expect(updated.Spec.Placement.ClusterAffinity).To(BeNil())
+	g.Expect(updated.Spec.Placement.ClusterAffinities).To(Equal([]karmadav1alpha1.ClusterAffinityTerm{
+		{AffinityName: "tier-1", ClusterAffinity: karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-fr-01"}}},
+		{AffinityName: "tier-2", ClusterAffinity: karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-de-01"}}},
+	}))
+
+	updatedPolicy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(policy), updatedPolicy)).To(Succeed())
+	g.Expect(updatedPolicy.Status.ActiveClusters).To(Equal([]string{"prd-fr-01"}))
+	g.Expect(updatedPolicy.Status.ClusterTiers).To(Equal([][]string{{"prd-fr-01"}, {"prd-de-01"}}))
+
+	// When the tiers are already set the target is not updated.
+	reconcileOnce(t, r, policy)
+	unchanged := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), unchanged)).To(Succeed())
+	g.Expect(unchanged.ResourceVersion).To(Equal(updated.ResourceVersion))
+}
+
+func TestReconcileCorrectsTierDrift(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	// The active tier is unchanged but the failover tier was edited.
+	policy := newTestPolicy("tiered-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Spec.PlacementMode = carbonawarev1alpha1.ClusterAffinitiesPlacementMode
+	policy.Status.ActiveClusters = []string{"prd-fr-01"}
+	policy.Status.ClusterTiers = [][]string{{"prd-fr-01"}, {"prd-de-01"}}
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+		Spec: karmadav1alpha1.PropagationSpec{
+			Placement: karmadav1alpha1.Placement{
+				ClusterAffinities: []karmadav1alpha1.ClusterAffinityTerm{
+					{AffinityName: "tier-1", ClusterAffinity: karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-fr-01"}}},
+					{AffinityName: "tier-2", ClusterAffinity: karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-es-01"}}},
+				},
+			},
+		},
+	}
+	c := newTestClient(t, policy, propagationPolicy)
+	recorder := record.NewFakeRecorder(10)
+	r := newTestReconciler(c)
+	r.Recorder = recorder
+
+	drift := DriftCorrectionsTotal.WithLabelValues("default", "tiered-policy")
+	before := testutil.ToFloat64(drift)
+	reconcileOnce(t, r, policy)
+
+	updated := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(propagationPolicy), updated)).To(Succeed())
+	g.Expect(placementTiers(updated.Spec.Placement)).To(Equal([][]string{{"prd-fr-01"}, {"prd-de-01"}}))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("DriftCorrected")))
+	g.Expect(testutil.ToFloat64(drift)).To(Equal(before + 1))
+}
+
+func TestReconcileDriftReportedAfterStatusUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	policy := newTestPolicy("failing-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Status.ActiveClusters = []string{"prd-fr-01"}
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+		Spec: karmadav1alpha1.PropagationSpec{
+			Placement: karmadav1alpha1.Placement{
+				ClusterAffinity: &karmadav1alpha1.ClusterAffinity{ClusterNames: []string{"prd-de-01"}},
+			},
+		},
+	}
+	c := interceptor.NewClient(newTestClient(t, policy, propagationPolicy).(client.WithWatch), interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			return errors.New("status update failed")
+		},
+	})
+	recorder := record.NewFakeRecorder(10)
+	r := newTestReconciler(c)
+	r.Recorder = recorder
+
+	drift := DriftCorrectionsTotal.WithLabelValues("default", "failing-policy")
+	before := testutil.ToFloat64(drift)
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+	g.Expect(err).To(MatchError("status update failed"))
+
+	// The placement was corrected but the drift is not reported as the
+	// status still has the previous tiers.
+	g.Expect(recorder.Events).NotTo(Receive())
+	g.Expect(testutil.ToFloat64(drift)).To(Equal(before))
+}
+
+func TestPlacementDrifted(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus
+		current  [][]string
+		expected bool
+	}{
+		{
+			name:    "never evaluated",
+			current: [][]string{{"prd-de-01"}},
+		},
+		{
+			name:    "same tiers in another order",
+			status:  carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus{ClusterTiers: [][]string{{"prd-fr-01", "prd-gb-01"}, {"prd-de-01"}}},
+			current: [][]string{{"prd-gb-01", "prd-fr-01"}, {"prd-de-01"}},
+		},
+		{
+			name:     "lower tier changed",
+			status:   carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus{ClusterTiers: [][]string{{"prd-fr-01"}, {"prd-de-01"}}},
+			current:  [][]string{{"prd-fr-01"}, {"prd-es-01"}},
+			expected: true,
+		},
+		{
+			name:     "tier removed",
+			status:   carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus{ClusterTiers: [][]string{{"prd-fr-01"}, {"prd-de-01"}}},
+			current:  [][]string{{"prd-fr-01"}},
+			expected: true,
+		},
+		{
+			name:    "tiers not recorded",
+			status:  carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus{ActiveClusters: []string{"prd-fr-01"}},
+			current: [][]string{{"prd-fr-01"}, {"prd-es-01"}},
+		},
+		{
+			name:     "tiers not recorded and active clusters changed",
+			status:   carbonawarev1alpha1.CarbonAwareKarmadaPolicyStatus{ActiveClusters: []string{"prd-fr-01"}},
+			current:  [][]string{},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(placementDrifted(tc.status, tc.current)).To(Equal(tc.expected))
+		})
+	}
+}
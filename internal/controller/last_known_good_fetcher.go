@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// LastKnownGoodFetcher keeps the last valid carbon intensity for each
+// location. If the provider fails the last reading is served, flagged as
+// stale, for up to maxStaleness after it expired so short provider outages
+// do not stall the operator.
+type LastKnownGoodFetcher struct {
+	fetcher      CarbonIntensityFetcher
+	maxStaleness time.Duration
+	now          func() time.Time
+
+	mu        sync.RWMutex
+	lastKnown map[string]CarbonIntensity
+}
+
+func NewLastKnownGoodFetcher(fetcher CarbonIntensityFetcher, maxStaleness time.Duration) *LastKnownGoodFetcher {
+	return &LastKnownGoodFetcher{
+		fetcher:      fetcher,
+		maxStaleness: maxStaleness,
+		now:          time.Now,
+		lastKnown:    map[string]CarbonIntensity{},
+	}
+}
+
+func (l *LastKnownGoodFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	result, err := l.fetcher.Fetch(ctx, clusterName, location)
+	if err == nil {
+		if result.CarbonIntensity.IsValid {
+			l.mu.Lock()
+			l.lastKnown[location] = result.CarbonIntensity
+			l.mu.Unlock()
+		}
+		return result, nil
+	}
+
+	l.mu.RLock()
+	lastKnown, ok := l.lastKnown[location]
+	l.mu.RUnlock()
+	if !ok || l.now().After(lastKnown.ValidTo.Add(l.maxStaleness)) {
+		return ClusterCarbonIntensity{}, err
+	}
+
+	reason := clusterStatusReason(err)
+	log.FromContext(ctx).Info("serving last known good carbon intensity",
+		"location", location, "validTo", lastKnown.ValidTo, "reason", reason, "error", err.Error())
+	ProviderErrorsTotal.WithLabelValues(l.fetcher.Provider(), string(reason)).Inc()
+
+	lastKnown.IsStale = l.now().After(lastKnown.ValidTo)
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: lastKnown,
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (l *LastKnownGoodFetcher) Provider() string {
+	return l.fetcher.Provider()
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// stubFetcher returns its current result or error.
+type stubFetcher struct {
+	result CarbonIntensity
+	err    error
+}
+
+func (f *stubFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	if f.err != nil {
+		return ClusterCarbonIntensity{}, f.err
+	}
+	return ClusterCarbonIntensity{CarbonIntensity: f.result, ClusterName: clusterName}, nil
+}
+
+func (f *stubFetcher) Provider() string {
+	return "Fake"
+}
+
+func TestLastKnownGoodFetcher(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	validTo := time.Date(2023, 9, 1, 11, 0, 0, 0, time.UTC)
+	reading := CarbonIntensity{IsValid: true, Location: "DE", Value: 380, ValidTo: validTo}
+	stub := &stubFetcher{result: reading}
+
+	now := validTo.Add(-time.Minute)
+	fetcher := NewLastKnownGoodFetcher(stub, time.Hour)
+	fetcher.now = func() time.Time { return now }
+
+	result, err := fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity).To(Equal(reading))
+
+	// An invalid result is returned but does not replace the stored reading.
+	stub.result = CarbonIntensity{Location: "DE"}
+	result, err = fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity.IsValid).To(BeFalse())
+
+	// While the reading is still valid it is served without being stale.
+	stub.err = ErrProviderUnavailable
+	result, err = fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.ClusterName).To(Equal("member1"))
+	g.Expect(result.CarbonIntensity.Value).To(Equal(380.0))
+	g.Expect(result.CarbonIntensity.IsStale).To(BeFalse())
+
+	// After it expires it is served as stale.
+	now = validTo.Add(time.Minute)
+	result, err = fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity.Value).To(Equal(380.0))
+	g.Expect(result.CarbonIntensity.IsStale).To(BeTrue())
+
+	// It is served up to and including the max staleness.
+	now = validTo.Add(time.Hour)
+	result, err = fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity.IsStale).To(BeTrue())
+
+	// Once the max staleness has passed the error is returned.
+	now = validTo.Add(time.Hour + time.Nanosecond)
+	_, err = fetcher.Fetch(ctx, "member1", "DE")
+	g.Expect(err).To(MatchError(ErrProviderUnavailable))
+}
+
+func TestLastKnownGoodFetcherWithoutReading(t *testing.T) {
+	g := NewWithT(t)
+
+	// Only invalid results have been seen so there is nothing to serve.
+	stub := &stubFetcher{result: CarbonIntensity{Location: "DE"}}
+	fetcher := NewLastKnownGoodFetcher(stub, time.Hour)
+
+	_, err := fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	stub.err = ErrUnknownLocation
+	_, err = fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).To(MatchError(ErrUnknownLocation))
+}
@@ -11,7 +11,31 @@ var (
 			Name: "carbon_aware_karmada_operator_cluster_carbon_intensity",
 			Help: "Cluster carbon intensity",
 		},
-		[]string{"cluster", "location", "active"},
+		[]string{"namespace", "policy", "cluster", "location", "active"},
+	)
+
+	ActiveClustersMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "carbon_aware_karmada_operator_active_clusters",
+			Help: "Number of active clusters selected for the policy",
+		},
+		[]string{"namespace", "policy"},
+	)
+
+	LastDecisionChangeMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "carbon_aware_karmada_operator_last_decision_change_timestamp_seconds",
+			Help: "Unix time the active clusters of the policy last changed",
+		},
+		[]string{"namespace", "policy"},
+	)
+
+	DriftCorrectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_drift_corrections_total",
+			Help: "Total number of times the cluster affinity of the Karmada policy was changed by someone else and corrected",
+		},
+		[]string{"namespace", "policy"},
 	)
 
 	ReconcilesTotal = prometheus.NewCounterVec(
@@ -19,7 +43,7 @@ var (
 			Name: "carbon_aware_karmada_operator_reconciles_total",
 			Help: "Total number of reconciles",
 		},
-		[]string{"app"},
+		[]string{"namespace", "policy"},
 	)
 
 	ReconcileErrorsTotal = prometheus.NewCounterVec(
@@ -27,13 +51,137 @@ var (
 			Name: "carbon_aware_karmada_operator_reconcile_errors_total",
 			Help: "Total number of reconcile errors",
 		},
-		[]string{"app"},
+		[]string{"namespace", "policy"},
+	)
+
+	ReconcileDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "carbon_aware_karmada_operator_reconcile_duration_seconds",
+			Help:    "Time taken to reconcile a policy",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"result"},
+	)
+
+	ProviderErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_provider_errors_total",
+			Help: "Total number of carbon intensity provider errors by reason",
+		},
+		[]string{"provider", "reason"},
+	)
+
+	CacheHitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_cache_hits_total",
+			Help: "Total number of carbon intensity cache hits",
+		},
+		[]string{"provider", "cache"},
+	)
+
+	CacheMissesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_cache_misses_total",
+			Help: "Total number of carbon intensity cache misses",
+		},
+		[]string{"provider"},
+	)
+
+	CacheEvictionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_cache_evictions_total",
+			Help: "Total number of carbon intensity cache evictions",
+		},
+		[]string{"provider", "cache", "reason"},
+	)
+
+	ProviderLatencySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "carbon_aware_karmada_operator_provider_latency_seconds",
+			Help:    "Time taken by carbon intensity provider requests",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"provider", "result"},
+	)
+
+	ProviderRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_provider_retries_total",
+			Help: "Total number of retried carbon intensity provider requests",
+		},
+		[]string{"provider"},
+	)
+
+	RateLimiterWaitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "carbon_aware_karmada_operator_rate_limiter_wait_seconds",
+			Help:    "Time spent waiting for the provider rate limiter",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"provider"},
+	)
+
+	CircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "carbon_aware_karmada_operator_circuit_breaker_state",
+			Help: "Provider circuit breaker state (0 closed, 1 half-open, 2 open)",
+		},
+		[]string{"provider"},
+	)
+
+	CircuitBreakerRejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "carbon_aware_karmada_operator_circuit_breaker_rejections_total",
+			Help: "Total number of provider requests rejected by the open circuit breaker",
+		},
+		[]string{"provider"},
 	)
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
 	metrics.Registry.MustRegister(CarbonIntensityMetric)
+	metrics.Registry.MustRegister(ActiveClustersMetric)
+	metrics.Registry.MustRegister(LastDecisionChangeMetric)
+	metrics.Registry.MustRegister(DriftCorrectionsTotal)
 	metrics.Registry.MustRegister(ReconcilesTotal)
 	metrics.Registry.MustRegister(ReconcileErrorsTotal)
+	metrics.Registry.MustRegister(ReconcileDurationSeconds)
+	metrics.Registry.MustRegister(ProviderErrorsTotal)
+	metrics.Registry.MustRegister(CacheHitsTotal)
+	metrics.Registry.MustRegister(CacheMissesTotal)
+	metrics.Registry.MustRegister(CacheEvictionsTotal)
+	metrics.Registry.MustRegister(ProviderLatencySeconds)
+	metrics.Registry.MustRegister(ProviderRetriesTotal)
+	metrics.Registry.MustRegister(RateLimiterWaitSeconds)
+	metrics.Registry.MustRegister(CircuitBreakerState)
+	metrics.Registry.MustRegister(CircuitBreakerRejectionsTotal)
+}
+
+// ProviderMetrics returns the metrics for calls to the carbon intensity
+// provider so they can also be registered with a registry other than the
+// controller-runtime one.
+func ProviderMetrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		ProviderErrorsTotal,
+		CacheHitsTotal,
+		CacheMissesTotal,
+		CacheEvictionsTotal,
+		ProviderLatencySeconds,
+		ProviderRetriesTotal,
+		RateLimiterWaitSeconds,
+		CircuitBreakerState,
+		CircuitBreakerRejectionsTotal,
+	}
+}
+
+// deletePolicyMetrics deletes the series of a deleted policy.
+func deletePolicyMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "policy": name}
+	CarbonIntensityMetric.DeletePartialMatch(labels)
+	ActiveClustersMetric.Delete(labels)
+	LastDecisionChangeMetric.Delete(labels)
+	DriftCorrectionsTotal.Delete(labels)
+	ReconcilesTotal.Delete(labels)
+	ReconcileErrorsTotal.Delete(labels)
 }
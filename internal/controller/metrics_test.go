@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// policySeries returns the number of series of each metric for policies in
+// the namespace.
+func policySeries(g Gomega, namespace string, collectors ...prometheus.Collector) map[string]int {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors...)
+	families, err := registry.Gather()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	series := map[string]int{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "namespace" && label.GetValue() == namespace {
+					series[family.GetName()]++
+				}
+			}
+		}
+	}
+
+	return series
+}
+
+func TestDeletePolicyMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "metrics-test"
+	for _, policy := range []string{"nginx-policy", "redis-policy"} {
+		CarbonIntensityMetric.WithLabelValues(namespace, policy, "member1", "DE", "true").Set(380)
+		CarbonIntensityMetric.WithLabelValues(namespace, policy, "member2", "FR", "false").Set(60)
+		ActiveClustersMetric.WithLabelValues(namespace, policy).Set(1)
+		LastDecisionChangeMetric.WithLabelValues(namespace, policy).SetToCurrentTime()
+		DriftCorrectionsTotal.WithLabelValues(namespace, policy).Inc()
+		ReconcilesTotal.WithLabelValues(namespace, policy).Inc()
+		ReconcileErrorsTotal.WithLabelValues(namespace, policy).Inc()
+	}
+	collectors := []prometheus.Collector{
+		CarbonIntensityMetric,
+		ActiveClustersMetric,
+		LastDecisionChangeMetric,
+		DriftCorrectionsTotal,
+		ReconcilesTotal,
+		ReconcileErrorsTotal,
+	}
+
+	deletePolicyMetrics(namespace, "nginx-policy")
+
+	// Only the series of the deleted policy are removed.
+	g.Expect(policySeries(g, namespace, collectors...)).To(Equal(map[string]int{
+		"carbon_aware_karmada_operator_cluster_carbon_intensity":               2,
+		"carbon_aware_karmada_operator_active_clusters":                        1,
+		"carbon_aware_karmada_operator_last_decision_change_timestamp_seconds": 1,
+		"carbon_aware_karmada_operator_drift_corrections_total":                1,
+		"carbon_aware_karmada_operator_reconciles_total":                       1,
+		"carbon_aware_karmada_operator_reconcile_errors_total":                 1,
+	}))
+
+	deletePolicyMetrics(namespace, "redis-policy")
+	g.Expect(policySeries(g, namespace, collectors...)).To(BeEmpty())
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	PrometheusProvider = "Prometheus"
+
+	defaultPrometheusScrapeInterval = time.Minute
+)
+
+// prometheusLocationPattern matches the locations that can be substituted
+// into a query. Other characters could change the meaning of the query.
+var prometheusLocationPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// PrometheusFetcher runs a PromQL query per location so carbon intensity
+// data that is already scraped into Prometheus can be used. The query must
+// contain a {location} placeholder and return a single sample.
+type PrometheusFetcher struct {
+	api            promv1.API
+	query          string
+	scrapeInterval time.Duration
+	units          string
+	now            func() time.Time
+}
+
+func NewPrometheusFetcher(address, query string, scrapeInterval time.Duration, units string) (*PrometheusFetcher, error) {
+	if !strings.Contains(query, locationPlaceholder) {
+		return nil, fmt.Errorf("prometheus query %s must contain %s", query, locationPlaceholder)
+	}
+	if scrapeInterval <= 0 {
+		scrapeInterval = defaultPrometheusScrapeInterval
+	}
+	if units == "" {
+		units = defaultUnits
+	}
+
+	client, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus client: %w", err)
+	}
+
+	return &PrometheusFetcher{
+		api:            promv1.NewAPI(client),
+		query:          query,
+		scrapeInterval: scrapeInterval,
+		units:          units,
+		now:            time.Now,
+	}, nil
+}
+
+func newPrometheusFetcherFromEnv() (*PrometheusFetcher, error) {
+	address, err := getEnvVar("PROMETHEUS_URL")
+	if err != nil {
+		return nil, err
+	}
+	query, err := getEnvVar("PROMETHEUS_QUERY")
+	if err != nil {
+		return nil, err
+	}
+
+	var scrapeInterval time.Duration
+	if val := os.Getenv("PROMETHEUS_SCRAPE_INTERVAL"); val != "" {
+		scrapeInterval, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PROMETHEUS_SCRAPE_INTERVAL: %w", err)
+		}
+	}
+
+	return NewPrometheusFetcher(address, query, scrapeInterval, os.Getenv("PROMETHEUS_UNITS"))
+}
+
+func (p *PrometheusFetcher) Fetch(ctx context.Context, clusterName, location string) (_ ClusterCarbonIntensity, err error) {
+	ctx, span := startFetchSpan(ctx, "PrometheusFetcher.Fetch", clusterName, location, PrometheusProvider)
+	defer func() { endSpan(span, err) }()
+
+	carbonIntensity, err := p.fetch(ctx, location)
+	if err != nil {
+		return ClusterCarbonIntensity{}, err
+	}
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: carbonIntensity,
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (p *PrometheusFetcher) Provider() string {
+	return PrometheusProvider
+}
+
+func (p *PrometheusFetcher) fetch(ctx context.Context, location string) (CarbonIntensity, error) {
+	if !prometheusLocationPattern.MatchString(location) {
+		return CarbonIntensity{}, fmt.Errorf("%w: location %q must match %s", ErrUnknownLocation, location, prometheusLocationPattern)
+	}
+	query := strings.ReplaceAll(p.query, locationPlaceholder, location)
+
+	result, _, err := p.api.Query(ctx, query, p.now())
+	if err != nil {
+		return CarbonIntensity{}, fmt.Errorf("failed to query prometheus for %s: %w", location, classifyProviderError(err))
+	}
+
+	return p.parse(location, result)
+}
+
+func (p *PrometheusFetcher) parse(location string, result model.Value) (CarbonIntensity, error) {
+	var sample *model.Sample
+
+	switch v := result.(type) {
+	case model.Vector:
+		if len(v) == 0 {
+			return CarbonIntensity{IsValid: false, Location: location}, nil
+		} else if len(v) > 1 {
+			return CarbonIntensity{}, fmt.Errorf("prometheus query for %s returned %d series, expected 1", location, len(v))
+		}
+		sample = v[0]
+	case *model.Scalar:
+		sample = &model.Sample{Value: v.Value, Timestamp: v.Timestamp}
+	default:
+		return CarbonIntensity{}, fmt.Errorf("unsupported prometheus result type %s", result.Type())
+	}
+
+	value := float64(sample.Value)
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return CarbonIntensity{}, fmt.Errorf("prometheus query for %s returned invalid value %v", location, value)
+	}
+	validFrom := sample.Timestamp.Time().UTC()
+
+	return CarbonIntensity{
+		IsValid:   true,
+		Location:  location,
+		Units:     p.units,
+		ValidFrom: validFrom,
+		ValidTo:   validFrom.Add(p.scrapeInterval),
+		Value:     value,
+	}, nil
+}
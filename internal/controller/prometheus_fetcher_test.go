@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func newPrometheusStandIn(result string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.URL.Path != "/api/v1/query" || r.Form.Get("query") != `grid_carbon_intensity{zone="DE"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"unexpected query"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":%s}`, result)
+	}))
+}
+
+func TestPrometheusFetcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		location    string
+		result      string
+		expected    CarbonIntensity
+		expectedErr string
+	}{
+		{
+			name:     "vector",
+			location: "DE",
+			result:   `{"resultType":"vector","result":[{"metric":{"zone":"DE"},"value":[1693564200,"301.5"]}]}`,
+			expected: CarbonIntensity{
+				IsValid:   true,
+				Location:  "DE",
+				Units:     defaultUnits,
+				ValidFrom: time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC),
+				ValidTo:   time.Date(2023, 9, 1, 10, 35, 0, 0, time.UTC),
+				Value:     301.5,
+			},
+		},
+		{
+			name:     "scalar",
+			location: "DE",
+			result:   `{"resultType":"scalar","result":[1693564200,"190"]}`,
+			expected: CarbonIntensity{
+				IsValid:   true,
+				Location:  "DE",
+				Units:     defaultUnits,
+				ValidFrom: time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC),
+				ValidTo:   time.Date(2023, 9, 1, 10, 35, 0, 0, time.UTC),
+				Value:     190,
+			},
+		},
+		{
+			name:     "no data",
+			location: "DE",
+			result:   `{"resultType":"vector","result":[]}`,
+			expected: CarbonIntensity{IsValid: false, Location: "DE"},
+		},
+		{
+			name:     "more than one series",
+			location: "DE",
+			result: `{"resultType":"vector","result":[
+				{"metric":{"zone":"DE"},"value":[1693564200,"301.5"]},
+				{"metric":{"zone":"DE"},"value":[1693564200,"190"]}]}`,
+			expectedErr: "returned 2 series",
+		},
+		{
+			name:        "NaN",
+			location:    "DE",
+			result:      `{"resultType":"vector","result":[{"metric":{},"value":[1693564200,"NaN"]}]}`,
+			expectedErr: "invalid value NaN",
+		},
+		{
+			name:        "infinite",
+			location:    "DE",
+			result:      `{"resultType":"scalar","result":[1693564200,"+Inf"]}`,
+			expectedErr: "invalid value +Inf",
+		},
+		{
+			name:        "query injection",
+			location:    `DE"} or vector(0) #`,
+			expectedErr: ErrUnknownLocation.Error(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			server := newPrometheusStandIn(tc.result)
+			defer server.Close()
+
+			fetcher, err := NewPrometheusFetcher(server.URL, `grid_carbon_intensity{zone="{location}"}`, 5*time.Minute, "")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			result, err := fetcher.Fetch(context.Background(), "member1", tc.location)
+			if tc.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.ClusterName).To(Equal("member1"))
+			g.Expect(result.CarbonIntensity).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestNewPrometheusFetcher(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewPrometheusFetcher("http://prometheus:9090", "grid_carbon_intensity", 0, "")
+	g.Expect(err).To(HaveOccurred())
+
+	fetcher, err := NewPrometheusFetcher("http://prometheus:9090", `grid_carbon_intensity{zone="{location}"}`, 0, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(fetcher.scrapeInterval).To(Equal(defaultPrometheusScrapeInterval))
+	g.Expect(fetcher.units).To(Equal(defaultUnits))
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+const (
+	// RankingAPIPath is the path prefix of the ranking API.
+	RankingAPIPath = "/api/v1/"
+)
+
+// RankingAPI serves the ranking of the clusters of each policy from the
+// policy status. The status is read from the manager cache so every replica
+// returns the ranking of the last reconcile by the leader without calling
+// the provider.
+type RankingAPI struct {
+	// Reader reads policies. It must be set before the API serves
+	// requests.
+	Reader client.Reader
+}
+
+// PolicyRanking is the ranking of the clusters of a policy.
+type PolicyRanking struct {
+	Namespace       string          `json:"namespace"`
+	Name            string          `json:"name"`
+	DesiredClusters int             `json:"desiredClusters"`
+	ActiveClusters  []string        `json:"activeClusters"`
+	Clusters        []RankedCluster `json:"clusters"`
+}
+
+// RankedCluster is a cluster in the ranking of a policy.
+type RankedCluster struct {
+	Rank            int                                     `json:"rank"`
+	Name            string                                  `json:"name"`
+	Location        string                                  `json:"location"`
+	Active          bool                                    `json:"active"`
+	CarbonIntensity *LocationIntensity                      `json:"carbonIntensity,omitempty"`
+	Excluded        string                                  `json:"excluded,omitempty"`
+	Reason          carbonawarev1alpha1.ClusterStatusReason `json:"reason,omitempty"`
+	Message         string                                  `json:"message,omitempty"`
+}
+
+// LocationIntensity is the cached carbon intensity of a location.
+type LocationIntensity struct {
+	Location  string    `json:"location"`
+	Value     float64   `json:"value"`
+	Units     string    `json:"units"`
+	ValidFrom time.Time `json:"validFrom"`
+	ValidTo   time.Time `json:"validTo"`
+	IsStale   bool      `json:"isStale"`
+}
+
+// newPolicyRanking returns the ranking of the policy from its status. The
+// clusters in the status are in rank order.
+func newPolicyRanking(policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy) PolicyRanking {
+	desiredClusters := 0
+	if policy.Spec.DesiredClusters != nil {
+		desiredClusters = int(*policy.Spec.DesiredClusters)
+	}
+	activeClusters := policy.Status.ActiveClusters
+	if activeClusters == nil {
+		activeClusters = []string{}
+	}
+
+	ranking := PolicyRanking{
+		Namespace:       policy.Namespace,
+		Name:            policy.Name,
+		DesiredClusters: desiredClusters,
+		ActiveClusters:  activeClusters,
+		Clusters:        make([]RankedCluster, 0, len(policy.Status.Clusters)),
+	}
+	for i, c := range policy.Status.Clusters {
+		cluster := RankedCluster{
+			Rank:     i + 1,
+			Name:     c.Name,
+			Location: c.Location,
+			Active:   containsString(activeClusters, c.Name),
+			Reason:   c.Reason,
+			Message:  c.Message,
+		}
+		if c.IsValid {
+			cluster.CarbonIntensity = locationIntensity(c)
+		}
+		switch {
+		case cluster.Active:
+		case !c.IsValid:
+			cluster.Excluded = excludedNoData(c.Reason)
+		default:
+			cluster.Excluded = excludedHigherIntensity(desiredClusters)
+		}
+		ranking.Clusters = append(ranking.Clusters, cluster)
+	}
+
+	return ranking
+}
+
+// policies returns the rankings sorted by namespace and name.
+func (a *RankingAPI) policies(ctx context.Context) ([]PolicyRanking, error) {
+	policyList := &carbonawarev1alpha1.CarbonAwareKarmadaPolicyList{}
+	if err := a.Reader.List(ctx, policyList); err != nil {
+		return nil, err
+	}
+
+	policies := make([]PolicyRanking, 0, len(policyList.Items))
+	for i := range policyList.Items {
+		policies = append(policies, newPolicyRanking(&policyList.Items[i]))
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Namespace != policies[j].Namespace {
+			return policies[i].Namespace < policies[j].Namespace
+		}
+		return policies[i].Name < policies[j].Name
+	})
+
+	return policies, nil
+}
+
+// locations returns the latest carbon intensity of each location used by
+// the policies sorted by location.
+func (a *RankingAPI) locations(ctx context.Context) ([]LocationIntensity, error) {
+	policies, err := a.policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[string]*LocationIntensity{}
+	for _, p := range policies {
+		for _, cluster := range p.Clusters {
+			ci := cluster.CarbonIntensity
+			if ci == nil {
+				continue
+			}
+			if current, ok := latest[ci.Location]; !ok || ci.ValidTo.After(current.ValidTo) {
+				latest[ci.Location] = ci
+			}
+		}
+	}
+
+	locations := make([]LocationIntensity, 0, len(latest))
+	for _, l := range latest {
+		locations = append(locations, *l)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].Location < locations[j].Location
+	})
+
+	return locations, nil
+}
+
+// ServeHTTP serves the ranking API. It is read only and serves:
+//
+//	GET /api/v1/locations
+//	GET /api/v1/policies
+//	GET /api/v1/policies/{namespace}/{name}
+func (a *RankingAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, RankingAPIPath), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "locations":
+		locations, err := a.locations(ctx)
+		writeJSON(w, locations, err)
+	case len(parts) == 1 && parts[0] == "policies":
+		policies, err := a.policies(ctx)
+		writeJSON(w, policies, err)
+	case len(parts) == 3 && parts[0] == "policies":
+		policy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+		err := a.Reader.Get(ctx, types.NamespacedName{Namespace: parts[1], Name: parts[2]}, policy)
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "policy not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, newPolicyRanking(policy), err)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// locationIntensity parses the carbon intensity in the cluster status.
+func locationIntensity(c carbonawarev1alpha1.ClusterStatus) *LocationIntensity {
+	value, _ := strconv.ParseFloat(c.CarbonIntensity.Value, 64)
+	validFrom, _ := time.Parse(time.RFC3339, c.CarbonIntensity.ValidFrom)
+	validTo, _ := time.Parse(time.RFC3339, c.CarbonIntensity.ValidTo)
+
+	return &LocationIntensity{
+		Location:  c.Location,
+		Value:     value,
+		Units:     c.CarbonIntensity.Units,
+		ValidFrom: validFrom,
+		ValidTo:   validTo,
+		IsStale:   c.IsStale,
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+func TestRankingAPI(t *testing.T) {
+	g := NewWithT(t)
+
+	validTo := time.Date(2023, 9, 1, 11, 0, 0, 0, time.UTC)
+	clusters := []ClusterCarbonIntensity{
+		{ClusterName: "prd-de-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "DE", Value: 380, ValidTo: validTo}},
+		{ClusterName: "prd-fr-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "FR", Value: 60, ValidTo: validTo}},
+		{ClusterName: "prd-es-01", CarbonIntensity: CarbonIntensity{IsValid: false, Location: "ES"}},
+	}
+	rankings := RankClusters(clusters, nil, 1)
+
+	// The ranking is read from the status written by the controller.
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	policy.Status.ActiveClusters = ActiveClusters(rankings)
+	for _, r := range rankings {
+		policy.Status.Clusters = append(policy.Status.Clusters, clusterStatus(r))
+	}
+	api := &RankingAPI{Reader: newTestClient(t, policy)}
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	get := func(path string, v interface{}) int {
+		resp, err := http.Get(server.URL + path)
+		g.Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			g.Expect(json.NewDecoder(resp.Body).Decode(v)).To(Succeed())
+		}
+		return resp.StatusCode
+	}
+
+	locations := []LocationIntensity{}
+	g.Expect(get("/api/v1/locations", &locations)).To(Equal(http.StatusOK))
+	g.Expect(locations).To(HaveLen(2))
+	g.Expect(locations[0].Location).To(Equal("DE"))
+	g.Expect(locations[1].Value).To(Equal(60.0))
+	g.Expect(locations[1].ValidTo).To(Equal(validTo))
+
+	ranking := PolicyRanking{}
+	g.Expect(get("/api/v1/policies/default/nginx-policy", &ranking)).To(Equal(http.StatusOK))
+	g.Expect(ranking.DesiredClusters).To(Equal(1))
+	g.Expect(ranking.ActiveClusters).To(Equal([]string{"prd-fr-01"}))
+	g.Expect(ranking.Clusters).To(HaveLen(3))
+	for i, r := range rankings {
+		g.Expect(ranking.Clusters[i].Rank).To(Equal(i + 1))
+		g.Expect(ranking.Clusters[i].Name).To(Equal(r.ClusterName))
+		g.Expect(ranking.Clusters[i].Active).To(Equal(r.Active))
+		g.Expect(ranking.Clusters[i].Excluded).To(Equal(r.Excluded))
+		g.Expect(ranking.Clusters[i].Reason).To(Equal(r.Reason))
+	}
+	g.Expect(ranking.Clusters[2].CarbonIntensity).To(BeNil())
+
+	policies := []PolicyRanking{}
+	g.Expect(get("/api/v1/policies", &policies)).To(Equal(http.StatusOK))
+	g.Expect(policies).To(HaveLen(1))
+
+	g.Expect(get("/api/v1/policies/default/missing", nil)).To(Equal(http.StatusNotFound))
+	g.Expect(get("/api/v1/unknown", nil)).To(Equal(http.StatusNotFound))
+
+	resp, err := http.Post(server.URL+"/api/v1/policies", "application/json", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+}
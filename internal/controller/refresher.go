@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+const (
+	refreshInterval = 30 * time.Second
+)
+
+// Refresher fetches the carbon intensity of the locations used by policies
+// shortly before it expires so reconciles do not block on provider calls.
+// When a value changes the policies using the location are reconciled.
+type Refresher struct {
+	fetcher      CarbonIntensityFetcher
+	refreshAfter time.Duration
+	timeout      time.Duration
+	now          func() time.Time
+	events       chan event.GenericEvent
+
+	mu        sync.Mutex
+	locations map[string]*refreshLocation
+	policies  map[types.NamespacedName][]string
+}
+
+type refreshLocation struct {
+	carbonIntensity CarbonIntensity
+	nextRefresh     time.Time
+	policies        map[types.NamespacedName]struct{}
+}
+
+// NewRefresher creates a refresher that refreshes locations the lead time
+// before their ValidTo.
+func NewRefresher(fetcher CarbonIntensityFetcher, lead, timeout time.Duration) *Refresher {
+	if timeout <= 0 {
+		timeout = defaultFetchTimeout
+	}
+
+	return &Refresher{
+		fetcher:      fetcher,
+		refreshAfter: -lead,
+		timeout:      timeout,
+		now:          time.Now,
+		events:       make(chan event.GenericEvent),
+		locations:    map[string]*refreshLocation{},
+		policies:     map[types.NamespacedName][]string{},
+	}
+}
+
+// Events returns the channel used to trigger reconciles of policies.
+func (r *Refresher) Events() <-chan event.GenericEvent {
+	return r.events
+}
+
+// Track records the locations used by the policy and their current carbon
+// intensity. Locations no longer used by the policy are untracked.
+func (r *Refresher) Track(policy types.NamespacedName, clusters []ClusterCarbonIntensity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.untrack(policy)
+
+	locations := []string{}
+	for _, c := range clusters {
+		location := c.CarbonIntensity.Location
+		loc, ok := r.locations[location]
+		if !ok {
+			loc = &refreshLocation{policies: map[types.NamespacedName]struct{}{}}
+			r.locations[location] = loc
+		}
+		loc.policies[policy] = struct{}{}
+
+		if c.CarbonIntensity.IsValid && c.CarbonIntensity.ValidTo.After(loc.carbonIntensity.ValidTo) {
+			loc.carbonIntensity = c.CarbonIntensity
+			loc.nextRefresh = c.CarbonIntensity.ValidTo.Add(r.refreshAfter)
+		}
+		locations = append(locations, location)
+	}
+	r.policies[policy] = locations
+}
+
+// Untrack removes the policy from all its locations.
+func (r *Refresher) Untrack(policy types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.untrack(policy)
+}
+
+func (r *Refresher) untrack(policy types.NamespacedName) {
+	for _, location := range r.policies[policy] {
+		loc, ok := r.locations[location]
+		if !ok {
+			continue
+		}
+		delete(loc.policies, policy)
+		if len(loc.policies) == 0 {
+			delete(r.locations, location)
+		}
+	}
+	delete(r.policies, policy)
+}
+
+// Start refreshes locations until the context is cancelled.
+func (r *Refresher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection is true so only the leader calls the provider.
+func (r *Refresher) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *Refresher) refresh(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("refresher")
+	now := r.now()
+
+	r.mu.Lock()
+	due := []string{}
+	for location, loc := range r.locations {
+		if loc.carbonIntensity.IsValid && !now.Before(loc.nextRefresh) {
+			due = append(due, location)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, location := range due {
+		fetchCtx, cancel := context.WithTimeout(withoutCache(ctx), r.timeout)
+		result, err := r.fetcher.Fetch(fetchCtx, "", location)
+		cancel()
+		if err != nil {
+			logger.Error(err, "unable to refresh carbon intensity", "location", location)
+		}
+
+		r.mu.Lock()
+		loc, ok := r.locations[location]
+		if !ok {
+			r.mu.Unlock()
+			continue
+		}
+
+		// Keep trying until a newer reading is available.
+		loc.nextRefresh = now.Add(refreshInterval)
+		carbonIntensity := result.CarbonIntensity
+		if err != nil || !carbonIntensity.IsValid || !carbonIntensity.ValidTo.After(loc.carbonIntensity.ValidTo) {
+			r.mu.Unlock()
+			continue
+		}
+
+		changed := carbonIntensity.Value != loc.carbonIntensity.Value
+		loc.carbonIntensity = carbonIntensity
+		loc.nextRefresh = carbonIntensity.ValidTo.Add(r.refreshAfter)
+		policies := make([]types.NamespacedName, 0, len(loc.policies))
+		for policy := range loc.policies {
+			policies = append(policies, policy)
+		}
+		r.mu.Unlock()
+
+		if !changed {
+			continue
+		}
+		logger.V(1).Info("carbon intensity changed", "location", location, "value", carbonIntensity.Value)
+		for _, policy := range policies {
+			select {
+			case r.events <- event.GenericEvent{Object: &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: policy.Name, Namespace: policy.Namespace},
+			}}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// locationFetcher returns the carbon intensity set for each location.
+type locationFetcher struct {
+	mu      sync.Mutex
+	results map[string]CarbonIntensity
+	calls   map[string]int
+}
+
+func (f *locationFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[location]++
+	return ClusterCarbonIntensity{CarbonIntensity: f.results[location], ClusterName: clusterName}, nil
+}
+
+func (f *locationFetcher) Provider() string {
+	return "Fake"
+}
+
+// refreshOnce runs a refresh and returns the policies it reconciled.
+func refreshOnce(r *Refresher) []types.NamespacedName {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.refresh(context.Background())
+	}()
+
+	policies := []types.NamespacedName{}
+	for {
+		select {
+		case e := <-r.Events():
+			policies = append(policies, client.ObjectKeyFromObject(e.Object))
+		case <-done:
+			return policies
+		}
+	}
+}
+
+func TestRefresher(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	reading := func(location string, value float64, validTo time.Time) CarbonIntensity {
+		return CarbonIntensity{IsValid: true, Location: location, ValidTo: validTo, Value: value}
+	}
+
+	fetcher := &locationFetcher{calls: map[string]int{}, results: map[string]CarbonIntensity{}}
+	r := NewRefresher(fetcher, 2*time.Minute, time.Second)
+	r.now = func() time.Time { return now }
+
+	nginx := types.NamespacedName{Name: "nginx-policy", Namespace: "default"}
+	redis := types.NamespacedName{Name: "redis-policy", Namespace: "default"}
+	r.Track(nginx, []ClusterCarbonIntensity{
+		{ClusterName: "member1", CarbonIntensity: reading("DE", 380, now.Add(time.Minute))},
+		{ClusterName: "member2", CarbonIntensity: reading("FR", 60, now.Add(time.Hour))},
+	})
+	r.Track(redis, []ClusterCarbonIntensity{
+		{ClusterName: "member1", CarbonIntensity: reading("DE", 380, now.Add(time.Minute))},
+	})
+
+	// DE expires within the lead time and its value changed so both
+	// policies using it are reconciled. FR is not due.
+	fetcher.results["DE"] = reading("DE", 250, now.Add(time.Hour))
+	g.Expect(refreshOnce(r)).To(ConsistOf(nginx, redis))
+	g.Expect(fetcher.calls).To(Equal(map[string]int{"DE": 1}))
+
+	// A newer reading with the same value does not reconcile policies.
+	now = now.Add(59 * time.Minute)
+	fetcher.results["DE"] = reading("DE", 250, now.Add(2*time.Hour))
+	fetcher.results["FR"] = reading("FR", 60, now.Add(2*time.Hour))
+	g.Expect(refreshOnce(r)).To(BeEmpty())
+	g.Expect(fetcher.calls).To(Equal(map[string]int{"DE": 2, "FR": 1}))
+
+	// Untracked locations are not refreshed.
+	r.Untrack(redis)
+	r.Untrack(nginx)
+	now = now.Add(3 * time.Hour)
+	g.Expect(refreshOnce(r)).To(BeEmpty())
+	g.Expect(fetcher.calls).To(Equal(map[string]int{"DE": 2, "FR": 1}))
+	g.Expect(r.locations).To(BeEmpty())
+}
+
+func TestRefresherRetriesUntilNewerReading(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	current := CarbonIntensity{IsValid: true, Location: "DE", ValidTo: now.Add(time.Minute), Value: 380}
+
+	fetcher := &locationFetcher{calls: map[string]int{}, results: map[string]CarbonIntensity{"DE": current}}
+	r := NewRefresher(fetcher, 2*time.Minute, time.Second)
+	r.now = func() time.Time { return now }
+
+	policy := types.NamespacedName{Name: "nginx-policy", Namespace: "default"}
+	r.Track(policy, []ClusterCarbonIntensity{{ClusterName: "member1", CarbonIntensity: current}})
+
+	g.Expect(refreshOnce(r)).To(BeEmpty())
+	g.Expect(r.locations["DE"].nextRefresh).To(Equal(now.Add(refreshInterval)))
+
+	// Not retried before the refresh interval.
+	g.Expect(refreshOnce(r)).To(BeEmpty())
+	g.Expect(fetcher.calls["DE"]).To(Equal(1))
+
+	now = now.Add(refreshInterval)
+	fetcher.results["DE"] = CarbonIntensity{IsValid: true, Location: "DE", ValidTo: now.Add(time.Hour), Value: 190}
+	g.Expect(refreshOnce(r)).To(ConsistOf(policy))
+	g.Expect(fetcher.calls["DE"]).To(Equal(2))
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"math/rand"
+	"time"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+const (
+	defaultMinRequeueInterval = time.Minute
+	defaultMaxRequeueInterval = time.Hour
+)
+
+// requeueAfter returns when the policy should be evaluated again. This is
+// when the earliest carbon intensity reading of its clusters expires unless
+// the policy sets an evaluation interval. Jitter is added so policies with
+// the same locations are not all reconciled at once.
+func (r *CarbonAwareKarmadaPolicyReconciler) requeueAfter(policy *carbonawarev1alpha1.CarbonAwareKarmadaPolicy, clusters []ClusterCarbonIntensity) time.Duration {
+	if policy.Spec.EvaluationInterval != nil && policy.Spec.EvaluationInterval.Duration > 0 {
+		return r.addJitter(policy.Spec.EvaluationInterval.Duration)
+	}
+
+	minInterval := defaultDuration(r.MinRequeueInterval, defaultMinRequeueInterval)
+	maxInterval := defaultDuration(r.MaxRequeueInterval, defaultMaxRequeueInterval)
+	now := time.Now()
+
+	var earliest time.Time
+	for _, c := range clusters {
+		validTo := c.CarbonIntensity.ValidTo
+		if !c.CarbonIntensity.IsValid || !validTo.After(now) {
+			continue
+		}
+		if earliest.IsZero() || validTo.Before(earliest) {
+			earliest = validTo
+		}
+	}
+
+	after := requeueInterval
+	if !earliest.IsZero() {
+		after = earliest.Sub(now)
+	}
+	if after < minInterval {
+		after = minInterval
+	} else if after > maxInterval {
+		after = maxInterval
+	}
+
+	return r.addJitter(after)
+}
+
+// addJitter adds up to RequeueJitter of the interval. Jitter is only added
+// so the data has expired when the policy is reconciled.
+func (r *CarbonAwareKarmadaPolicyReconciler) addJitter(interval time.Duration) time.Duration {
+	if r.RequeueJitter <= 0 {
+		return interval
+	}
+
+	maxJitter := int64(float64(interval) * r.RequeueJitter)
+	if maxJitter <= 0 {
+		return interval
+	}
+
+	return interval + time.Duration(rand.Int63n(maxJitter))
+}
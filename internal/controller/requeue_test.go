@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+func TestRequeueAfter(t *testing.T) {
+	now := time.Now()
+	cluster := func(isValid bool, validTo time.Time) ClusterCarbonIntensity {
+		return ClusterCarbonIntensity{CarbonIntensity: CarbonIntensity{IsValid: isValid, ValidTo: validTo}}
+	}
+
+	tests := []struct {
+		name               string
+		evaluationInterval time.Duration
+		clusters           []ClusterCarbonIntensity
+		expected           time.Duration
+	}{
+		{
+			name:     "earliest valid reading",
+			clusters: []ClusterCarbonIntensity{cluster(true, now.Add(40*time.Minute)), cluster(true, now.Add(20*time.Minute))},
+			expected: 20 * time.Minute,
+		},
+		{
+			name: "invalid and expired readings are ignored",
+			clusters: []ClusterCarbonIntensity{
+				cluster(false, now.Add(5*time.Minute)),
+				cluster(true, now.Add(-5*time.Minute)),
+				cluster(true, now.Add(30*time.Minute)),
+			},
+			expected: 30 * time.Minute,
+		},
+		{
+			name:     "clamped to min interval",
+			clusters: []ClusterCarbonIntensity{cluster(true, now.Add(10*time.Second))},
+			expected: 2 * time.Minute,
+		},
+		{
+			name:     "clamped to max interval",
+			clusters: []ClusterCarbonIntensity{cluster(true, now.Add(24*time.Hour))},
+			expected: 3 * time.Hour,
+		},
+		{
+			name:     "no valid readings",
+			clusters: []ClusterCarbonIntensity{cluster(false, time.Time{})},
+			expected: requeueInterval,
+		},
+		{
+			name:               "evaluation interval is not clamped",
+			evaluationInterval: 90 * time.Second,
+			clusters:           []ClusterCarbonIntensity{cluster(true, now.Add(20*time.Minute))},
+			expected:           90 * time.Second,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &CarbonAwareKarmadaPolicyReconciler{
+				MinRequeueInterval: 2 * time.Minute,
+				MaxRequeueInterval: 3 * time.Hour,
+			}
+			policy := &carbonawarev1alpha1.CarbonAwareKarmadaPolicy{}
+			if tc.evaluationInterval > 0 {
+				policy.Spec.EvaluationInterval = &metav1.Duration{Duration: tc.evaluationInterval}
+			}
+
+			g.Expect(r.requeueAfter(policy, tc.clusters)).To(BeNumerically("~", tc.expected, time.Second))
+
+			// Jitter only ever delays the requeue.
+			r.RequeueJitter = 0.1
+			for i := 0; i < 100; i++ {
+				after := r.requeueAfter(policy, tc.clusters)
+				g.Expect(after).To(BeNumerically(">=", tc.expected-time.Second))
+				g.Expect(after).To(BeNumerically("<=", tc.expected+tc.expected/10))
+			}
+		})
+	}
+}
+
+func TestAddJitter(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &CarbonAwareKarmadaPolicyReconciler{}
+	g.Expect(r.addJitter(time.Minute)).To(Equal(time.Minute))
+
+	r.RequeueJitter = 0.5
+	g.Expect(r.addJitter(time.Nanosecond)).To(Equal(time.Nanosecond))
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		after := r.addJitter(time.Minute)
+		g.Expect(after).To(BeNumerically(">=", time.Minute))
+		g.Expect(after).To(BeNumerically("<", 90*time.Second))
+		seen[after] = true
+	}
+	g.Expect(len(seen)).To(BeNumerically(">", 1))
+}
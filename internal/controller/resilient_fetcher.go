@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+// ResilienceOptions configures the middleware around the provider.
+type ResilienceOptions struct {
+	// RateLimit is the maximum requests per second to the provider.
+	// Zero disables rate limiting.
+	RateLimit float64
+	Burst     int
+
+	// MaxRetries is the number of retries for retryable errors.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// BreakerFailures is the number of consecutive failures that opens the
+	// circuit. Zero disables the circuit breaker.
+	BreakerFailures     int
+	BreakerOpenDuration time.Duration
+}
+
+// cacheChecker is implemented by fetchers that cache readings so cache hits
+// can bypass the middleware.
+type cacheChecker interface {
+	IsCached(location string) bool
+}
+
+// ResilientFetcher wraps a fetcher with a token bucket rate limiter, retries
+// with jittered exponential backoff and a circuit breaker.
+type ResilientFetcher struct {
+	fetcher CarbonIntensityFetcher
+	limiter *rate.Limiter
+	options ResilienceOptions
+	now     func() time.Time
+
+	mu        sync.Mutex
+	state     circuitState
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func NewResilientFetcher(fetcher CarbonIntensityFetcher, options ResilienceOptions) *ResilientFetcher {
+	limit := rate.Inf
+	if options.RateLimit > 0 {
+		limit = rate.Limit(options.RateLimit)
+	}
+	if options.Burst <= 0 {
+		options.Burst = 1
+	}
+
+	r := &ResilientFetcher{
+		fetcher: fetcher,
+		limiter: rate.NewLimiter(limit, options.Burst),
+		options: options,
+		now:     time.Now,
+	}
+	r.setState(circuitClosed)
+
+	return r
+}
+
+func (r *ResilientFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	if c, ok := r.fetcher.(cacheChecker); ok && c.IsCached(location) && !isWithoutCache(ctx) {
+		return r.fetcher.Fetch(ctx, clusterName, location)
+	}
+
+	var (
+		result ClusterCarbonIntensity
+		err    error
+	)
+
+	for attempt := 0; ; attempt++ {
+		if !r.allow() {
+			CircuitBreakerRejectionsTotal.WithLabelValues(r.Provider()).Inc()
+			return ClusterCarbonIntensity{}, fmt.Errorf("%w: %w", ErrProviderUnavailable, ErrCircuitOpen)
+		}
+
+		start := r.now()
+		if waitErr := r.limiter.Wait(ctx); waitErr != nil {
+			r.release()
+			return ClusterCarbonIntensity{}, rateLimiterError(ctx, waitErr)
+		}
+		RateLimiterWaitSeconds.WithLabelValues(r.Provider()).Observe(r.now().Sub(start).Seconds())
+
+		start = r.now()
+		result, err = r.fetcher.Fetch(ctx, clusterName, location)
+		ProviderLatencySeconds.WithLabelValues(r.Provider(), providerResult(err)).Observe(r.now().Sub(start).Seconds())
+		r.record(err)
+
+		if err == nil || !isRetryable(err) || attempt >= r.options.MaxRetries {
+			return result, err
+		}
+
+		delay := r.backoff(attempt)
+		log.FromContext(ctx).V(1).Info("retrying carbon intensity fetch",
+			"location", location, "attempt", attempt+1, "delay", delay, "error", err.Error())
+		ProviderRetriesTotal.WithLabelValues(r.Provider()).Inc()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ClusterCarbonIntensity{}, err
+		}
+	}
+}
+
+func (r *ResilientFetcher) Provider() string {
+	return r.fetcher.Provider()
+}
+
+// backoff returns the delay before the retry using exponential backoff with
+// full jitter.
+func (r *ResilientFetcher) backoff(attempt int) time.Duration {
+	delay := r.options.RetryBaseDelay << attempt
+	if delay <= 0 || delay > r.options.RetryMaxDelay {
+		delay = r.options.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// allow returns whether a request can be made. When the circuit is open a
+// single trial request is allowed after the open duration.
+func (r *ResilientFetcher) allow() bool {
+	if r.options.BreakerFailures <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.state {
+	case circuitOpen:
+		if r.now().Before(r.openUntil) {
+			return false
+		}
+		r.setState(circuitHalfOpen)
+		r.trial = true
+		return true
+	case circuitHalfOpen:
+		if r.trial {
+			return false
+		}
+		r.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// release allows another trial request if the request was not made.
+func (r *ResilientFetcher) release() {
+	r.mu.Lock()
+	r.trial = false
+	r.mu.Unlock()
+}
+
+// record updates the circuit breaker with the result of a request. Unknown
+// locations are not failures of the provider.
+func (r *ResilientFetcher) record(err error) {
+	if r.options.BreakerFailures <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trial = false
+
+	if err == nil || errors.Is(err, ErrUnknownLocation) {
+		r.failures = 0
+		r.setState(circuitClosed)
+		return
+	}
+
+	r.failures++
+	if r.state == circuitHalfOpen || r.failures >= r.options.BreakerFailures {
+		r.openUntil = r.now().Add(r.options.BreakerOpenDuration)
+		r.setState(circuitOpen)
+	}
+}
+
+func (r *ResilientFetcher) setState(state circuitState) {
+	r.state = state
+	CircuitBreakerState.WithLabelValues(r.Provider()).Set(float64(state))
+}
+
+// rateLimiterError returns the error for a failed rate limiter wait. The
+// limiter fails straight away if the wait would exceed the context deadline
+// so this is reported as a timeout rather than the provider rate limit.
+func rateLimiterError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("rate limiter: %w", ctxErr)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return fmt.Errorf("rate limiter: %w: %w", context.DeadlineExceeded, err)
+	}
+
+	return fmt.Errorf("%w: rate limiter: %w", ErrProviderRateLimited, err)
+}
+
+// providerResult is the result label of the provider latency metric.
+func providerResult(err error) string {
+	if err == nil {
+		return "success"
+	}
+
+	return string(clusterStatusReason(err))
+}
+
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrProviderRateLimited) ||
+		errors.Is(err, ErrProviderUnavailable) ||
+		errors.Is(err, ErrProviderTransport)
+}
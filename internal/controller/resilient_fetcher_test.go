@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+// scriptedFetcher returns the errors in order and then succeeds.
+type scriptedFetcher struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (f *scriptedFetcher) Fetch(ctx context.Context, clusterName, location string) (ClusterCarbonIntensity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return ClusterCarbonIntensity{}, err
+		}
+	}
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: CarbonIntensity{IsValid: true, Location: location, Value: 100},
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (f *scriptedFetcher) Provider() string {
+	return "Fake"
+}
+
+func TestResilientFetcherRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		errs          []error
+		maxRetries    int
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name:          "retryable errors then success",
+			errs:          []error{ErrProviderUnavailable, ErrProviderRateLimited, ErrProviderTransport},
+			maxRetries:    3,
+			expectedCalls: 4,
+		},
+		{
+			name:          "retry cap",
+			errs:          []error{ErrProviderUnavailable, ErrProviderUnavailable, ErrProviderUnavailable},
+			maxRetries:    2,
+			expectedCalls: 3,
+			expectedErr:   ErrProviderUnavailable,
+		},
+		{
+			name:          "not retryable",
+			errs:          []error{ErrUnknownLocation},
+			maxRetries:    3,
+			expectedCalls: 1,
+			expectedErr:   ErrUnknownLocation,
+		},
+		{
+			name:          "auth errors are not retried",
+			errs:          []error{ErrProviderAuth},
+			maxRetries:    3,
+			expectedCalls: 1,
+			expectedErr:   ErrProviderAuth,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			fetcher := &scriptedFetcher{errs: tc.errs}
+			r := NewResilientFetcher(fetcher, ResilienceOptions{
+				MaxRetries:     tc.maxRetries,
+				RetryBaseDelay: time.Millisecond,
+				RetryMaxDelay:  time.Millisecond,
+			})
+
+			result, err := r.Fetch(context.Background(), "member1", "DE")
+			g.Expect(fetcher.calls).To(Equal(tc.expectedCalls))
+			if tc.expectedErr != nil {
+				g.Expect(errors.Is(err, tc.expectedErr)).To(BeTrue(), "unexpected error %v", err)
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.CarbonIntensity.IsValid).To(BeTrue())
+		})
+	}
+}
+
+func TestResilientFetcherBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	r := NewResilientFetcher(&scriptedFetcher{}, ResilienceOptions{
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  time.Second,
+	})
+
+	for i := 0; i < 100; i++ {
+		g.Expect(r.backoff(0)).To(BeNumerically("<=", 100*time.Millisecond))
+		g.Expect(r.backoff(2)).To(BeNumerically("<=", 400*time.Millisecond))
+		g.Expect(r.backoff(5)).To(BeNumerically("<=", time.Second))
+		// Large attempts overflow the shift and use the max delay.
+		g.Expect(r.backoff(70)).To(BeNumerically("<=", time.Second))
+		g.Expect(r.backoff(70)).To(BeNumerically(">=", 0))
+	}
+
+	r = NewResilientFetcher(&scriptedFetcher{}, ResilienceOptions{})
+	g.Expect(r.backoff(3)).To(BeZero())
+}
+
+func TestResilientFetcherCircuitBreaker(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	fetcher := &scriptedFetcher{}
+	r := NewResilientFetcher(fetcher, ResilienceOptions{
+		BreakerFailures:     2,
+		BreakerOpenDuration: time.Minute,
+	})
+	r.now = func() time.Time { return now }
+
+	// Unknown locations are not provider failures.
+	fetcher.errs = []error{ErrUnknownLocation, ErrProviderUnavailable, ErrUnknownLocation, ErrProviderUnavailable}
+	for i := 0; i < 4; i++ {
+		_, _ = r.Fetch(ctx, "member1", "DE")
+	}
+	g.Expect(r.state).To(Equal(circuitClosed))
+
+	// Consecutive failures open the circuit.
+	fetcher.errs = []error{ErrProviderUnavailable, ErrProviderUnavailable}
+	for i := 0; i < 2; i++ {
+		_, _ = r.Fetch(ctx, "member1", "DE")
+	}
+	g.Expect(r.state).To(Equal(circuitOpen))
+
+	calls := fetcher.calls
+	_, err := r.Fetch(ctx, "member1", "DE")
+	g.Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+	g.Expect(errors.Is(err, ErrProviderUnavailable)).To(BeTrue())
+	g.Expect(fetcher.calls).To(Equal(calls))
+
+	// After the open duration a failed trial request opens it again.
+	now = now.Add(time.Minute)
+	fetcher.errs = []error{ErrProviderTransport}
+	_, err = r.Fetch(ctx, "member1", "DE")
+	g.Expect(errors.Is(err, ErrProviderTransport)).To(BeTrue())
+	g.Expect(r.state).To(Equal(circuitOpen))
+	_, err = r.Fetch(ctx, "member1", "DE")
+	g.Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+
+	// Only one trial request is allowed while half open.
+	now = now.Add(time.Minute)
+	g.Expect(r.allow()).To(BeTrue())
+	g.Expect(r.state).To(Equal(circuitHalfOpen))
+	g.Expect(r.allow()).To(BeFalse())
+	r.release()
+
+	// A successful trial request closes the circuit.
+	_, err = r.Fetch(ctx, "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(r.state).To(Equal(circuitClosed))
+	g.Expect(r.failures).To(BeZero())
+}
+
+func TestResilientFetcherRateLimiterContext(t *testing.T) {
+	g := NewWithT(t)
+
+	fetcher := &scriptedFetcher{}
+	r := NewResilientFetcher(fetcher, ResilienceOptions{RateLimit: 0.001, Burst: 1})
+
+	// The first request uses the burst.
+	_, err := r.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Waiting for the next token would exceed the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = r.Fetch(ctx, "member1", "DE")
+	g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue(), "unexpected error %v", err)
+	g.Expect(errors.Is(err, ErrProviderRateLimited)).To(BeFalse())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = r.Fetch(ctx, "member1", "DE")
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue(), "unexpected error %v", err)
+	g.Expect(errors.Is(err, ErrProviderRateLimited)).To(BeFalse())
+	g.Expect(fetcher.calls).To(Equal(1))
+
+	// The timeout is reported as a transport error rather than a rate limit.
+	_, fetchErrors := FetchClusters(context.Background(), r, []carbonawarev1alpha1.ClusterLocation{{Name: "member1", Location: "DE"}}, 1, time.Second)
+	g.Expect(clusterStatusReason(fetchErrors["member1"])).To(Equal(carbonawarev1alpha1.ProviderTransportErrorReason))
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+// ClusterRanking is a cluster of a policy ranked by carbon intensity.
+type ClusterRanking struct {
+	ClusterCarbonIntensity
+
+	// Active is true if the cluster is selected.
+	Active bool
+	// Reason and Message explain why the cluster has no valid carbon
+	// intensity.
+	Reason  carbonawarev1alpha1.ClusterStatusReason
+	Message string
+	// Excluded explains why the cluster is not selected.
+	Excluded string
+}
+
+// RankClusters sorts the clusters by carbon intensity and selects up to
+// desiredClusters clusters with valid data. Clusters without valid data are
+// ranked last in their original order. fetchErrors is keyed by cluster
+// name. This is the selection used by the controller.
+func RankClusters(clusters []ClusterCarbonIntensity, fetchErrors map[string]error, desiredClusters int) []ClusterRanking {
+	sorted := make([]ClusterCarbonIntensity, len(clusters))
+	copy(sorted, clusters)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].CarbonIntensity, sorted[j].CarbonIntensity
+		if a.IsValid != b.IsValid {
+			return a.IsValid
+		}
+		return a.IsValid && a.Value < b.Value
+	})
+
+	rankings := make([]ClusterRanking, 0, len(sorted))
+	active := 0
+	for _, c := range sorted {
+		ranking := ClusterRanking{ClusterCarbonIntensity: c}
+
+		switch {
+		case !c.CarbonIntensity.IsValid:
+			if fetchErr, ok := fetchErrors[c.ClusterName]; ok {
+				ranking.Reason = clusterStatusReason(fetchErr)
+				ranking.Message = fetchErr.Error()
+			} else {
+				ranking.Reason = carbonawarev1alpha1.NoDataReason
+				ranking.Message = "provider returned no carbon intensity data"
+			}
+			ranking.Excluded = excludedNoData(ranking.Reason)
+		case active < desiredClusters:
+			ranking.Active = true
+			active++
+		default:
+			ranking.Excluded = excludedHigherIntensity(desiredClusters)
+		}
+
+		rankings = append(rankings, ranking)
+	}
+
+	return rankings
+}
+
+func excludedNoData(reason carbonawarev1alpha1.ClusterStatusReason) string {
+	return fmt.Sprintf("no valid carbon intensity (%s)", reason)
+}
+
+func excludedHigherIntensity(desiredClusters int) string {
+	return fmt.Sprintf("higher carbon intensity than the %d desired clusters", desiredClusters)
+}
+
+// ActiveClusters returns the names of the selected clusters in rank order.
+func ActiveClusters(rankings []ClusterRanking) []string {
+	activeClusters := []string{}
+	for _, r := range rankings {
+		if r.Active {
+			activeClusters = append(activeClusters, r.ClusterName)
+		}
+	}
+
+	return activeClusters
+}
+
+// ClusterTiers groups the clusters with valid data in rank order into
+// tiers of size clusters. The first tier is the active clusters.
+func ClusterTiers(rankings []ClusterRanking, size int) [][]string {
+	tiers := [][]string{ActiveClusters(rankings)}
+	if size <= 0 {
+		return tiers
+	}
+
+	tier := []string{}
+	for _, r := range rankings {
+		if r.Active || !r.CarbonIntensity.IsValid {
+			continue
+		}
+		tier = append(tier, r.ClusterName)
+		if len(tier) == size {
+			tiers = append(tiers, tier)
+			tier = []string{}
+		}
+	}
+	if len(tier) > 0 {
+		tiers = append(tiers, tier)
+	}
+
+	return tiers
+}
+
+func clusterStatus(r ClusterRanking) carbonawarev1alpha1.ClusterStatus {
+	status := carbonawarev1alpha1.ClusterStatus{
+		IsStale:  r.CarbonIntensity.IsStale,
+		IsValid:  r.CarbonIntensity.IsValid,
+		Location: r.CarbonIntensity.Location,
+		Name:     r.ClusterName,
+		Reason:   r.Reason,
+		Message:  r.Message,
+	}
+	if r.CarbonIntensity.IsValid {
+		status.CarbonIntensity = carbonawarev1alpha1.ClusterCarbonIntensityStatus{
+			Units:     r.CarbonIntensity.Units,
+			ValidFrom: r.CarbonIntensity.ValidFrom.Format(time.RFC3339),
+			ValidTo:   r.CarbonIntensity.ValidTo.Format(time.RFC3339),
+			Value:     fmt.Sprintf("%.2f", r.CarbonIntensity.Value),
+		}
+	}
+
+	return status
+}
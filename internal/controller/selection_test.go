@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+func TestRankClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	clusters := []ClusterCarbonIntensity{
+		{ClusterName: "prd-pl-01", CarbonIntensity: CarbonIntensity{IsValid: false, Location: "PL"}},
+		{ClusterName: "prd-de-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "DE", Value: 380}},
+		{ClusterName: "prd-fr-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "FR", Value: 60}},
+		{ClusterName: "prd-es-01", CarbonIntensity: CarbonIntensity{IsValid: false, Location: "ES"}},
+		{ClusterName: "prd-gb-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "GB", Value: 210}},
+	}
+	fetchErrors := map[string]error{
+		"prd-es-01": fmt.Errorf("failed to get carbon intensity for ES: %w", ErrUnknownLocation),
+	}
+
+	rankings := RankClusters(clusters, fetchErrors, 2)
+	g.Expect(ActiveClusters(rankings)).To(Equal([]string{"prd-fr-01", "prd-gb-01"}))
+
+	// Clusters without valid data are ranked after all valid clusters.
+	order := []string{}
+	for _, r := range rankings {
+		order = append(order, r.ClusterName)
+	}
+	g.Expect(order).To(Equal([]string{"prd-fr-01", "prd-gb-01", "prd-de-01", "prd-pl-01", "prd-es-01"}))
+
+	byName := map[string]ClusterRanking{}
+	for _, r := range rankings {
+		byName[r.ClusterName] = r
+	}
+	g.Expect(byName["prd-fr-01"].Excluded).To(BeEmpty())
+	g.Expect(byName["prd-de-01"].Active).To(BeFalse())
+	g.Expect(byName["prd-de-01"].Excluded).To(ContainSubstring("higher carbon intensity"))
+	g.Expect(byName["prd-es-01"].Reason).To(Equal(carbonawarev1alpha1.UnknownLocationReason))
+	g.Expect(errors.Is(fetchErrors["prd-es-01"], ErrUnknownLocation)).To(BeTrue())
+	g.Expect(byName["prd-pl-01"].Reason).To(Equal(carbonawarev1alpha1.NoDataReason))
+
+	// The input order is not changed.
+	g.Expect(clusters[0].ClusterName).To(Equal("prd-pl-01"))
+}
+
+func TestClusterTiers(t *testing.T) {
+	g := NewWithT(t)
+
+	clusters := []ClusterCarbonIntensity{
+		{ClusterName: "prd-de-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "DE", Value: 380}},
+		{ClusterName: "prd-fr-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "FR", Value: 60}},
+		{ClusterName: "prd-es-01", CarbonIntensity: CarbonIntensity{IsValid: false, Location: "ES"}},
+		{ClusterName: "prd-gb-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "GB", Value: 210}},
+		{ClusterName: "prd-pl-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "PL", Value: 650}},
+		{ClusterName: "prd-se-01", CarbonIntensity: CarbonIntensity{IsValid: true, Location: "SE", Value: 30}},
+	}
+
+	rankings := RankClusters(clusters, nil, 2)
+	g.Expect(ClusterTiers(rankings, 2)).To(Equal([][]string{
+		{"prd-se-01", "prd-fr-01"},
+		{"prd-gb-01", "prd-de-01"},
+		{"prd-pl-01"},
+	}))
+
+	rankings = RankClusters(clusters, nil, 0)
+	g.Expect(ClusterTiers(rankings, 0)).To(Equal([][]string{{}}))
+}
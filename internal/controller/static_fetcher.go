@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	StaticProvider = "Static"
+)
+
+// StaticIntensityData is the format of the static provider data file. Each
+// location has a default value and an optional time of day profile.
+//
+//	locations:
+//	  DE:
+//	    units: gCO2e/kWh
+//	    value: 350
+//	    profile:
+//	    - from: "00:00"
+//	      value: 280
+//	    - from: "10:00"
+//	      value: 190
+type StaticIntensityData struct {
+	Locations map[string]StaticLocation `json:"locations"`
+}
+
+type StaticLocation struct {
+	Profile []StaticProfileEntry `json:"profile,omitempty"`
+	Units   string               `json:"units,omitempty"`
+	Value   float64              `json:"value"`
+}
+
+// StaticProfileEntry sets the value from a UTC time of day in HH:MM format
+// until the next entry in the profile.
+type StaticProfileEntry struct {
+	From  string  `json:"from"`
+	Value float64 `json:"value"`
+}
+
+// StaticFetcher reads carbon intensity data from a local YAML or CSV file so
+// the operator can run without network access. The file can be a mounted
+// ConfigMap and is reloaded when it changes.
+type StaticFetcher struct {
+	now  func() time.Time
+	path string
+
+	mu        sync.Mutex
+	locations map[string]staticLocation
+	modTime   time.Time
+}
+
+type staticLocation struct {
+	slots []staticSlot
+	units string
+}
+
+type staticSlot struct {
+	from  time.Duration
+	value float64
+}
+
+func NewStaticFetcher(path string) (*StaticFetcher, error) {
+	s := &StaticFetcher{
+		now:  time.Now,
+		path: path,
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *StaticFetcher) Fetch(ctx context.Context, clusterName, location string) (_ ClusterCarbonIntensity, err error) {
+	ctx, span := startFetchSpan(ctx, "StaticFetcher.Fetch", clusterName, location, StaticProvider)
+	defer func() { endSpan(span, err) }()
+
+	if err := s.reload(); err != nil {
+		log.FromContext(ctx).Error(err, "unable to reload static data file, using previous data")
+	}
+
+	s.mu.Lock()
+	loc, ok := s.locations[location]
+	s.mu.Unlock()
+	if !ok {
+		return ClusterCarbonIntensity{}, fmt.Errorf("location %s not in static data file: %w", location, ErrUnknownLocation)
+	}
+
+	return ClusterCarbonIntensity{
+		CarbonIntensity: loc.carbonIntensity(location, s.now().UTC()),
+		ClusterName:     clusterName,
+	}, nil
+}
+
+func (s *StaticFetcher) Provider() string {
+	return StaticProvider
+}
+
+// reload parses the data file if it has been modified since it was last read.
+// If the file is invalid the previous data is kept and the error is only
+// returned once for each modification.
+func (s *StaticFetcher) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat static data file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.locations != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	if s.locations != nil {
+		s.modTime = info.ModTime()
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open static data file: %w", err)
+	}
+	defer f.Close()
+
+	var data StaticIntensityData
+	switch strings.ToLower(filepath.Ext(s.path)) {
+	case ".csv":
+		data, err = parseStaticCSV(f)
+	default:
+		data, err = parseStaticYAML(f)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse static data file %s: %w", s.path, err)
+	}
+
+	locations, err := newStaticLocations(data)
+	if err != nil {
+		return fmt.Errorf("invalid static data file %s: %w", s.path, err)
+	}
+
+	s.locations = locations
+	s.modTime = info.ModTime()
+
+	return nil
+}
+
+func (l staticLocation) carbonIntensity(location string, now time.Time) CarbonIntensity {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	sinceMidnight := now.Sub(midnight)
+
+	// Slots are sorted and the first slot always starts at midnight.
+	i := sort.Search(len(l.slots), func(i int) bool {
+		return l.slots[i].from > sinceMidnight
+	}) - 1
+
+	validTo := midnight.Add(24 * time.Hour)
+	if i+1 < len(l.slots) {
+		validTo = midnight.Add(l.slots[i+1].from)
+	}
+
+	return CarbonIntensity{
+		IsValid:   true,
+		Location:  location,
+		Units:     l.units,
+		ValidFrom: midnight.Add(l.slots[i].from),
+		ValidTo:   validTo,
+		Value:     l.slots[i].value,
+	}
+}
+
+func newStaticLocations(data StaticIntensityData) (map[string]staticLocation, error) {
+	locations := make(map[string]staticLocation, len(data.Locations))
+
+	for name, loc := range data.Locations {
+		units := loc.Units
+		if units == "" {
+			units = defaultUnits
+		}
+
+		if err := validateStaticValue(loc.Value); err != nil {
+			return nil, fmt.Errorf("location %s: %w", name, err)
+		}
+
+		// The default value applies from midnight until the first profile entry.
+		slots := []staticSlot{{from: 0, value: loc.Value}}
+		for _, p := range loc.Profile {
+			from, err := parseTimeOfDay(p.From)
+			if err != nil {
+				return nil, fmt.Errorf("location %s: %w", name, err)
+			}
+			if err := validateStaticValue(p.Value); err != nil {
+				return nil, fmt.Errorf("location %s from %s: %w", name, p.From, err)
+			}
+			if from == 0 {
+				slots[0].value = p.Value
+				continue
+			}
+			slots = append(slots, staticSlot{from: from, value: p.Value})
+		}
+		sort.Slice(slots, func(i, j int) bool {
+			return slots[i].from < slots[j].from
+		})
+
+		locations[name] = staticLocation{
+			slots: slots,
+			units: units,
+		}
+	}
+
+	return locations, nil
+}
+
+// validateStaticValue rejects values that cannot be a carbon intensity.
+func validateStaticValue(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return fmt.Errorf("value %v must be a finite number that is not negative", value)
+	}
+
+	return nil
+}
+
+func parseStaticYAML(r io.Reader) (StaticIntensityData, error) {
+	var data StaticIntensityData
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return data, err
+	}
+	err = yaml.UnmarshalStrict(b, &data)
+
+	return data, err
+}
+
+// parseStaticCSV parses rows in the format location,from,value,units. The
+// from and units columns may be empty and a header row is optional.
+func parseStaticCSV(r io.Reader) (StaticIntensityData, error) {
+	data := StaticIntensityData{Locations: map[string]StaticLocation{}}
+
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return data, err
+	}
+
+	for i, record := range records {
+		if len(record) < 3 {
+			return data, fmt.Errorf("line %d: expected at least 3 columns", i+1)
+		}
+		if i == 0 && strings.EqualFold(record[0], "location") {
+			continue
+		}
+
+		name, from := record[0], record[1]
+		value, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return data, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		loc := data.Locations[name]
+		if len(record) > 3 && record[3] != "" {
+			loc.Units = record[3]
+		}
+		if from == "" {
+			loc.Value = value
+		} else {
+			loc.Profile = append(loc.Profile, StaticProfileEntry{From: from, Value: value})
+		}
+		data.Locations[name] = loc
+	}
+
+	return data, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time of day %q must be in HH:MM format", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const staticTestYAML = `locations:
+  DE:
+    value: 350
+    profile:
+    - from: "10:00"
+      value: 190
+    - from: "18:00"
+      value: 410
+  FR:
+    units: kgCO2e/MWh
+    value: 55
+`
+
+func writeStaticFile(t *testing.T, path, data string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseStaticYAML(t *testing.T) {
+	g := NewWithT(t)
+
+	data, err := parseStaticYAML(strings.NewReader(staticTestYAML))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data.Locations).To(Equal(map[string]StaticLocation{
+		"DE": {
+			Value: 350,
+			Profile: []StaticProfileEntry{
+				{From: "10:00", Value: 190},
+				{From: "18:00", Value: 410},
+			},
+		},
+		"FR": {Units: "kgCO2e/MWh", Value: 55},
+	}))
+
+	_, err = parseStaticYAML(strings.NewReader("locations:\n  DE:\n    vaule: 350\n"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestParseStaticCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		expected    map[string]StaticLocation
+		expectedErr bool
+	}{
+		{
+			name: "with header and profile",
+			csv: `location,from,value,units
+# comment
+DE,,350,
+DE,10:00,190,
+FR,,55,kgCO2e/MWh
+`,
+			expected: map[string]StaticLocation{
+				"DE": {Value: 350, Profile: []StaticProfileEntry{{From: "10:00", Value: 190}}},
+				"FR": {Units: "kgCO2e/MWh", Value: 55},
+			},
+		},
+		{
+			name:     "without header",
+			csv:      "DE,,350\n",
+			expected: map[string]StaticLocation{"DE": {Value: 350}},
+		},
+		{
+			name:        "too few columns",
+			csv:         "DE,350\n",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid value",
+			csv:         "DE,,high\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			data, err := parseStaticCSV(strings.NewReader(tc.csv))
+			if tc.expectedErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(data.Locations).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestStaticFetcher(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "intensity.yaml")
+	writeStaticFile(t, path, staticTestYAML, time.Now().Add(-time.Hour))
+
+	fetcher, err := NewStaticFetcher(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	fetcher.now = func() time.Time { return time.Date(2023, 9, 1, 12, 30, 0, 0, time.UTC) }
+
+	result, err := fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.ClusterName).To(Equal("member1"))
+	g.Expect(result.CarbonIntensity).To(Equal(CarbonIntensity{
+		IsValid:   true,
+		Location:  "DE",
+		Units:     defaultUnits,
+		ValidFrom: time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC),
+		ValidTo:   time.Date(2023, 9, 1, 18, 0, 0, 0, time.UTC),
+		Value:     190,
+	}))
+
+	_, err = fetcher.Fetch(context.Background(), "member2", "atlantis")
+	g.Expect(errors.Is(err, ErrUnknownLocation)).To(BeTrue(), "unexpected error %v", err)
+}
+
+func TestStaticFetcherInvalidValues(t *testing.T) {
+	for _, data := range []string{"DE,,-1\n", "DE,,NaN\n", "DE,,+Inf\n", "DE,,350\nDE,10:00,-5\n"} {
+		t.Run(data, func(t *testing.T) {
+			g := NewWithT(t)
+
+			path := filepath.Join(t.TempDir(), "intensity.csv")
+			writeStaticFile(t, path, data, time.Now())
+
+			_, err := NewStaticFetcher(path)
+			g.Expect(err).To(MatchError(ContainSubstring("must be a finite number")))
+		})
+	}
+}
+
+func TestStaticFetcherReload(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "intensity.csv")
+	modTime := time.Now().Add(-time.Hour)
+	writeStaticFile(t, path, "DE,,350\n", modTime)
+
+	fetcher, err := NewStaticFetcher(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The file is not read again until it is modified.
+	writeStaticFile(t, path, "DE,,200\n", modTime)
+	result, err := fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity.Value).To(Equal(350.0))
+
+	modTime = modTime.Add(time.Minute)
+	writeStaticFile(t, path, "DE,,200\n", modTime)
+	result, err = fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity.Value).To(Equal(200.0))
+
+	// A bad edit keeps the last good data.
+	modTime = modTime.Add(time.Minute)
+	writeStaticFile(t, path, "DE,,high\n", modTime)
+	result, err = fetcher.Fetch(context.Background(), "member1", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.CarbonIntensity.IsValid).To(BeTrue())
+	g.Expect(result.CarbonIntensity.Value).To(Equal(200.0))
+
+	// A bad file is rejected on startup.
+	_, err = NewStaticFetcher(path)
+	g.Expect(err).To(HaveOccurred())
+}
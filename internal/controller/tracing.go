@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/rossf7/carbon-aware-karmada-operator/internal/controller"
+)
+
+// tracer returns the tracer from the global provider so it uses the
+// provider configured in main.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startFetchSpan starts the span for a provider fetch so every provider
+// reports the same attributes.
+func startFetchSpan(ctx context.Context, name, clusterName, location, provider string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(
+		attribute.String("cluster", clusterName),
+		attribute.String("location", location),
+		attribute.String("provider", provider),
+	))
+}
+
+// endSpan records the error, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	karmadav1alpha1 "github.com/karmada-io/karmada/pkg/apis/policy/v1alpha1"
+	. "github.com/onsi/gomega"
+	gridprovider "github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+type fakeGridProvider struct {
+	calls int
+}
+
+func (f *fakeGridProvider) GetCarbonIntensity(ctx context.Context, location string) ([]gridprovider.CarbonIntensity, error) {
+	f.calls++
+	return []gridprovider.CarbonIntensity{{
+		Location:  location,
+		Units:     defaultUnits,
+		ValidFrom: time.Now(),
+		ValidTo:   time.Now().Add(time.Hour),
+		Value:     map[string]float64{"DE": 380, "FR": 60}[location],
+	}}, nil
+}
+
+func newFakeGridIntensityFetcher(provider gridprovider.Interface) *GridIntensityFetcher {
+	return &GridIntensityFetcher{
+		cache:         ttlcache.New[string, CarbonIntensity](),
+		negativeCache: ttlcache.New[string, negativeCacheEntry](),
+		provider:      provider,
+		providerName:  "Fake",
+		minTTL:        defaultCacheMinTTL,
+		maxTTL:        defaultCacheMaxTTL,
+		negativeTTL:   defaultCacheNegativeTTL,
+	}
+}
+
+// setupTestTracing sets a global tracer provider that records spans in memory.
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = tp.Shutdown(context.Background())
+	})
+
+	return exporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestGridIntensityFetcherTracing(t *testing.T) {
+	g := NewWithT(t)
+	exporter := setupTestTracing(t)
+
+	provider := &fakeGridProvider{}
+	fetcher := newFakeGridIntensityFetcher(provider)
+
+	for i := 0; i < 2; i++ {
+		_, err := fetcher.Fetch(context.Background(), "prd-de-01", "DE")
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(provider.calls).To(Equal(1))
+
+	spans := exporter.GetSpans()
+	g.Expect(spans).To(HaveLen(2))
+	for i, cacheHit := range []bool{false, true} {
+		g.Expect(spans[i].Name).To(Equal("GridIntensityFetcher.Fetch"))
+		attrs := spanAttributes(spans[i])
+		g.Expect(attrs["location"].AsString()).To(Equal("DE"))
+		g.Expect(attrs["provider"].AsString()).To(Equal("Fake"))
+		g.Expect(attrs["cluster"].AsString()).To(Equal("prd-de-01"))
+		g.Expect(attrs["cache.hit"].AsBool()).To(Equal(cacheHit))
+	}
+}
+
+func TestProviderFetcherTracing(t *testing.T) {
+	g := NewWithT(t)
+	exporter := setupTestTracing(t)
+
+	path := filepath.Join(t.TempDir(), "intensity.csv")
+	writeStaticFile(t, path, "DE,,380\n", time.Now().Add(-time.Hour))
+	static, err := NewStaticFetcher(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"value":60}`)
+	}))
+	defer server.Close()
+	httpFetcher, err := NewHTTPFetcher(HTTPFetcherConfig{
+		URLTemplate: server.URL + "/{location}",
+		ValuePath:   ".value",
+	}, server.Client())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = static.Fetch(context.Background(), "prd-de-01", "DE")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = static.Fetch(context.Background(), "prd-us-01", "atlantis")
+	g.Expect(err).To(HaveOccurred())
+	_, err = httpFetcher.Fetch(context.Background(), "prd-fr-01", "FR")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expected := []struct {
+		name     string
+		cluster  string
+		location string
+		provider string
+		status   codes.Code
+	}{
+		{"StaticFetcher.Fetch", "prd-de-01", "DE", StaticProvider, codes.Unset},
+		{"StaticFetcher.Fetch", "prd-us-01", "atlantis", StaticProvider, codes.Error},
+		{"HTTPFetcher.Fetch", "prd-fr-01", "FR", HTTPProvider, codes.Unset},
+	}
+
+	spans := exporter.GetSpans()
+	g.Expect(spans).To(HaveLen(len(expected)))
+	for i, e := range expected {
+		g.Expect(spans[i].Name).To(Equal(e.name))
+		g.Expect(spans[i].Status.Code).To(Equal(e.status))
+		attrs := spanAttributes(spans[i])
+		g.Expect(attrs["cluster"].AsString()).To(Equal(e.cluster))
+		g.Expect(attrs["location"].AsString()).To(Equal(e.location))
+		g.Expect(attrs["provider"].AsString()).To(Equal(e.provider))
+	}
+}
+
+func TestReconcileTracing(t *testing.T) {
+	g := NewWithT(t)
+	exporter := setupTestTracing(t)
+
+	policy := newTestPolicy("nginx-policy", carbonawarev1alpha1.PropagationPolicy, "nginx-propagation")
+	propagationPolicy := &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-propagation", Namespace: "default"},
+	}
+	c := newTestClient(t, policy, propagationPolicy)
+	reconcileOnce(t, newTestReconciler(c), policy)
+
+	updated := &karmadav1alpha1.PropagationPolicy{}
+	g.Expect(c.Get(context.Background(), types.NamespacedName{Name: "nginx-propagation", Namespace: "default"}, updated)).To(Succeed())
+	g.Expect(updated.Spec.Placement.ClusterAffinity.ClusterNames).To(Equal([]string{"prd-fr-01"}))
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	g.Expect(spans).To(HaveKey("Reconcile"))
+	g.Expect(spans).To(HaveKey("FetchCarbonIntensity"))
+	g.Expect(spans).To(HaveKey("GridIntensityFetcher.Fetch"))
+	g.Expect(spans).To(HaveKey("UpdateKarmadaTarget"))
+	g.Expect(spans).To(HaveKey("UpdateStatus"))
+
+	root := spans["Reconcile"]
+	g.Expect(spanAttributes(root)["policy"].AsString()).To(Equal("nginx-policy"))
+	for _, name := range []string{"FetchCarbonIntensity", "UpdateKarmadaTarget", "UpdateStatus"} {
+		g.Expect(spans[name].Parent.SpanID()).To(Equal(root.SpanContext.SpanID()), name)
+	}
+	g.Expect(spans["GridIntensityFetcher.Fetch"].Parent.SpanID()).To(Equal(spans["FetchCarbonIntensity"].SpanContext.SpanID()))
+}
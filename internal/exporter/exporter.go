@@ -0,0 +1,154 @@
+// Package exporter exposes carbon intensity metrics for a list of locations
+// without requiring Karmada or the CarbonAwareKarmadaPolicy controller.
+package exporter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/rossf7/carbon-aware-karmada-operator/internal/controller"
+)
+
+// Exporter periodically fetches the carbon intensity of its locations and
+// sets the metrics.
+type Exporter struct {
+	fetcher    controller.CarbonIntensityFetcher
+	forecaster controller.CarbonIntensityForecaster
+	locations  []string
+	interval   time.Duration
+	timeout    time.Duration
+	logger     logr.Logger
+
+	carbonIntensity   *prometheus.GaugeVec
+	forecast          *prometheus.GaugeVec
+	forecastValidFrom *prometheus.GaugeVec
+	validTo           *prometheus.GaugeVec
+	fetchErrors       *prometheus.CounterVec
+}
+
+// New creates an exporter for the locations. The forecaster is optional and
+// forecast series are only exported when it is set.
+func New(fetcher controller.CarbonIntensityFetcher, forecaster controller.CarbonIntensityForecaster,
+	locations []string, interval, timeout time.Duration, logger logr.Logger) *Exporter {
+	return &Exporter{
+		fetcher:    fetcher,
+		forecaster: forecaster,
+		locations:  locations,
+		interval:   interval,
+		timeout:    timeout,
+		logger:     logger,
+		carbonIntensity: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "carbon_aware_karmada_operator_location_carbon_intensity",
+				Help: "Location carbon intensity",
+			},
+			[]string{"location", "provider", "units"},
+		),
+		forecast: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "carbon_aware_karmada_operator_location_carbon_intensity_forecast",
+				Help: "Location carbon intensity forecast by step from the current forecast data point",
+			},
+			[]string{"location", "provider", "units", "step"},
+		),
+		forecastValidFrom: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "carbon_aware_karmada_operator_location_carbon_intensity_forecast_valid_from_seconds",
+				Help: "Unix time the location carbon intensity forecast step is valid from",
+			},
+			[]string{"location", "provider", "step"},
+		),
+		validTo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "carbon_aware_karmada_operator_location_carbon_intensity_valid_to_seconds",
+				Help: "Unix time until the location carbon intensity is valid",
+			},
+			[]string{"location", "provider"},
+		),
+		fetchErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "carbon_aware_karmada_operator_location_fetch_errors_total",
+				Help: "Total number of errors fetching location carbon intensity",
+			},
+			[]string{"location", "provider"},
+		),
+	}
+}
+
+// Register registers the exporter metrics.
+func (e *Exporter) Register(registry prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{e.carbonIntensity, e.forecast, e.forecastValidFrom, e.validTo, e.fetchErrors} {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start fetches the locations every interval until the context is cancelled.
+func (e *Exporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.export(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Exporter) export(ctx context.Context) {
+	provider := e.fetcher.Provider()
+
+	for _, location := range e.locations {
+		fetchCtx, cancel := context.WithTimeout(ctx, e.timeout)
+		result, err := e.fetcher.Fetch(fetchCtx, location, location)
+		cancel()
+		if err != nil {
+			e.logger.Error(err, "unable to get carbon intensity", "location", location)
+			e.fetchErrors.WithLabelValues(location, provider).Inc()
+			continue
+		}
+
+		// Series for locations without valid data are removed rather than
+		// reporting a value of zero.
+		e.carbonIntensity.DeletePartialMatch(prometheus.Labels{"location": location})
+		e.validTo.DeleteLabelValues(location, provider)
+		if ci := result.CarbonIntensity; ci.IsValid {
+			e.carbonIntensity.WithLabelValues(location, provider, ci.Units).Set(ci.Value)
+			e.validTo.WithLabelValues(location, provider).Set(float64(ci.ValidTo.Unix()))
+		}
+
+		if e.forecaster == nil {
+			continue
+		}
+
+		fetchCtx, cancel = context.WithTimeout(ctx, e.timeout)
+		forecast, err := e.forecaster.Forecast(fetchCtx, location)
+		cancel()
+		if err != nil {
+			e.logger.Error(err, "unable to get carbon intensity forecast", "location", location)
+			e.fetchErrors.WithLabelValues(location, provider).Inc()
+			continue
+		}
+
+		// Steps are labelled by their position so the number of series is
+		// bounded by the length of the forecast rather than growing with time.
+		e.forecast.DeletePartialMatch(prometheus.Labels{"location": location})
+		e.forecastValidFrom.DeletePartialMatch(prometheus.Labels{"location": location})
+		for i, f := range forecast {
+			step := strconv.Itoa(i)
+			e.forecast.WithLabelValues(location, provider, f.Units, step).Set(f.Value)
+			e.forecastValidFrom.WithLabelValues(location, provider, step).Set(float64(f.ValidFrom.Unix()))
+		}
+	}
+}
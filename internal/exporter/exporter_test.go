@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/rossf7/carbon-aware-karmada-operator/internal/controller"
+)
+
+type fakeForecaster struct {
+	start time.Time
+}
+
+func (f *fakeForecaster) Fetch(ctx context.Context, clusterName, location string) (controller.ClusterCarbonIntensity, error) {
+	return controller.ClusterCarbonIntensity{
+		CarbonIntensity: controller.CarbonIntensity{
+			IsValid:   true,
+			Location:  location,
+			Units:     "gCO2e/kWh",
+			ValidFrom: f.start,
+			ValidTo:   f.start.Add(5 * time.Minute),
+			Value:     300,
+		},
+		ClusterName: clusterName,
+	}, nil
+}
+
+func (f *fakeForecaster) Provider() string {
+	return "Fake"
+}
+
+func (f *fakeForecaster) Forecast(ctx context.Context, location string) ([]controller.CarbonIntensity, error) {
+	forecast := []controller.CarbonIntensity{}
+	for i := 0; i < 3; i++ {
+		validFrom := f.start.Add(time.Duration(i) * 5 * time.Minute)
+		forecast = append(forecast, controller.CarbonIntensity{
+			IsValid:   true,
+			Location:  location,
+			Units:     "gCO2e/kWh",
+			ValidFrom: validFrom,
+			ValidTo:   validFrom.Add(5 * time.Minute),
+			Value:     float64(200 + i),
+		})
+	}
+
+	return forecast, nil
+}
+
+func TestExporterForecastSeries(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	f := &fakeForecaster{start: start}
+	e := New(f, f, []string{"westeurope"}, time.Minute, time.Second, logr.Discard())
+	g.Expect(e.Register(prometheus.NewRegistry())).To(Succeed())
+
+	// The number of series stays the same as the forecast moves forward.
+	for i := 0; i < 3; i++ {
+		e.export(context.Background())
+		g.Expect(testutil.CollectAndCount(e.forecast)).To(Equal(3))
+		g.Expect(testutil.CollectAndCount(e.forecastValidFrom)).To(Equal(3))
+		g.Expect(testutil.ToFloat64(e.forecastValidFrom.WithLabelValues("westeurope", "Fake", "1"))).
+			To(Equal(float64(f.start.Add(5 * time.Minute).Unix())))
+		f.start = f.start.Add(5 * time.Minute)
+	}
+
+	g.Expect(testutil.ToFloat64(e.forecast.WithLabelValues("westeurope", "Fake", "gCO2e/kWh", "2"))).To(Equal(202.0))
+	g.Expect(testutil.ToFloat64(e.carbonIntensity.WithLabelValues("westeurope", "Fake", "gCO2e/kWh"))).To(Equal(300.0))
+}
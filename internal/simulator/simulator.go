@@ -0,0 +1,253 @@
+// Package simulator replays historical carbon intensity data through the
+// cluster selection used by the controller.
+package simulator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+	"github.com/rossf7/carbon-aware-karmada-operator/internal/controller"
+)
+
+// Sample is the carbon intensity of a location from a point in time.
+type Sample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Location  string    `json:"location"`
+	Value     float64   `json:"value"`
+}
+
+// PlacementChange is a change of the active clusters.
+type PlacementChange struct {
+	Timestamp      time.Time `json:"timestamp"`
+	ActiveClusters []string  `json:"activeClusters"`
+	Added          []string  `json:"added"`
+	Removed        []string  `json:"removed"`
+}
+
+// Result is the outcome of a simulation. Carbon is the sum of the carbon
+// intensity of the active clusters multiplied by the hours they were active,
+// which is the emissions for a load of 1 kW in each cluster.
+type Result struct {
+	Start            time.Time         `json:"start"`
+	End              time.Time         `json:"end"`
+	Steps            int               `json:"steps"`
+	Changes          []PlacementChange `json:"changes"`
+	Migrations       int               `json:"migrations"`
+	BaselineClusters []string          `json:"baselineClusters"`
+	CarbonAware      float64           `json:"carbonAware"`
+	Baseline         float64           `json:"baseline"`
+	Saved            float64           `json:"saved"`
+	SavedPercent     float64           `json:"savedPercent"`
+}
+
+// LoadSamples reads samples from a CSV file with the columns
+// timestamp,location,value or a JSON array of samples.
+func LoadSamples(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSV(f)
+	case ".json":
+		samples := []Sample{}
+		err = json.NewDecoder(f).Decode(&samples)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		return samples, nil
+	default:
+		return nil, fmt.Errorf("unsupported file type %s, must be .csv or .json", path)
+	}
+}
+
+func parseCSV(r io.Reader) ([]Sample, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []Sample{}
+	for i, record := range records {
+		if i == 0 && record[0] == "timestamp" {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp %q: %w", i+1, record[0], err)
+		}
+		value, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value %q: %w", i+1, record[2], err)
+		}
+		samples = append(samples, Sample{Timestamp: timestamp, Location: record[1], Value: value})
+	}
+
+	return samples, nil
+}
+
+// Simulate evaluates the clusters at each timestamp in the samples. The
+// latest sample for each location is used until there is a newer one.
+// Locations without data are treated like a failed fetch so if no cluster
+// has data the placement is unchanged, as in the controller.
+//
+// The baseline is a static placement. If it is empty the first
+// desiredClusters clusters are used.
+func Simulate(locations []carbonawarev1alpha1.ClusterLocation, desiredClusters int, baseline []string, samples []Sample) (Result, error) {
+	if len(samples) == 0 {
+		return Result{}, fmt.Errorf("no samples")
+	}
+	if len(baseline) == 0 {
+		for i := 0; i < desiredClusters && i < len(locations); i++ {
+			baseline = append(baseline, locations[i].Name)
+		}
+	}
+	for _, name := range baseline {
+		if !hasCluster(locations, name) {
+			return Result{}, fmt.Errorf("baseline cluster %s is not in the cluster locations", name)
+		}
+	}
+
+	sorted := make([]Sample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	timestamps := []time.Time{}
+	for i, s := range sorted {
+		if i == 0 || !s.Timestamp.Equal(sorted[i-1].Timestamp) {
+			timestamps = append(timestamps, s.Timestamp)
+		}
+	}
+
+	result := Result{
+		Start:            timestamps[0],
+		End:              timestamps[len(timestamps)-1],
+		Steps:            len(timestamps),
+		BaselineClusters: baseline,
+	}
+
+	latest := map[string]float64{}
+	active := []string{}
+	next := 0
+	for i, timestamp := range timestamps {
+		for ; next < len(sorted) && !sorted[next].Timestamp.After(timestamp); next++ {
+			latest[sorted[next].Location] = sorted[next].Value
+		}
+
+		clusters := []controller.ClusterCarbonIntensity{}
+		fetchErrors := map[string]error{}
+		for _, loc := range locations {
+			value, ok := latest[loc.Location]
+			if !ok {
+				fetchErrors[loc.Name] = fmt.Errorf("no data for %s: %w", loc.Location, controller.ErrUnknownLocation)
+			}
+			clusters = append(clusters, controller.ClusterCarbonIntensity{
+				ClusterName:     loc.Name,
+				CarbonIntensity: controller.CarbonIntensity{IsValid: ok, Location: loc.Location, Value: value},
+			})
+		}
+
+		rankings := controller.RankClusters(clusters, fetchErrors, desiredClusters)
+		selected := controller.ActiveClusters(rankings)
+		if len(selected) == 0 && len(fetchErrors) > 0 {
+			selected = active
+		}
+
+		if i == 0 || !sameClusters(active, selected) {
+			added, removed := diff(active, selected)
+			if i > 0 {
+				result.Migrations += len(added)
+			}
+			result.Changes = append(result.Changes, PlacementChange{
+				Timestamp:      timestamp,
+				ActiveClusters: selected,
+				Added:          added,
+				Removed:        removed,
+			})
+		}
+		active = selected
+
+		// The last step has no duration as there is no next timestamp.
+		if i+1 == len(timestamps) {
+			break
+		}
+		hours := timestamps[i+1].Sub(timestamp).Hours()
+		result.CarbonAware += carbon(locations, active, latest) * hours
+		result.Baseline += carbon(locations, baseline, latest) * hours
+	}
+
+	result.Saved = result.Baseline - result.CarbonAware
+	if result.Baseline > 0 {
+		result.SavedPercent = result.Saved / result.Baseline * 100
+	}
+
+	return result, nil
+}
+
+func carbon(locations []carbonawarev1alpha1.ClusterLocation, clusters []string, latest map[string]float64) float64 {
+	total := 0.0
+	for _, loc := range locations {
+		if hasName(clusters, loc.Name) {
+			total += latest[loc.Location]
+		}
+	}
+
+	return total
+}
+
+func diff(before, after []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for _, name := range after {
+		if !hasName(before, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range before {
+		if !hasName(after, name) {
+			removed = append(removed, name)
+		}
+	}
+
+	return added, removed
+}
+
+func sameClusters(a, b []string) bool {
+	added, removed := diff(a, b)
+	return len(added) == 0 && len(removed) == 0
+}
+
+func hasCluster(locations []carbonawarev1alpha1.ClusterLocation, name string) bool {
+	for _, loc := range locations {
+		if loc.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	carbonawarev1alpha1 "github.com/rossf7/carbon-aware-karmada-operator/api/v1alpha1"
+)
+
+var locations = []carbonawarev1alpha1.ClusterLocation{
+	{Name: "member1", Location: "FR"},
+	{Name: "member2", Location: "ES"},
+}
+
+func hour(h int) time.Time {
+	return time.Date(2023, 9, 1, h, 0, 0, 0, time.UTC)
+}
+
+func TestSimulate(t *testing.T) {
+	g := NewWithT(t)
+
+	samples := []Sample{
+		{Timestamp: hour(0), Location: "FR", Value: 60},
+		{Timestamp: hour(0), Location: "ES", Value: 150},
+		{Timestamp: hour(1), Location: "ES", Value: 50},
+		// FR keeps its last value of 60.
+		{Timestamp: hour(2), Location: "ES", Value: 100},
+		{Timestamp: hour(3), Location: "FR", Value: 70},
+	}
+
+	result, err := Simulate(locations, 1, nil, samples)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(result.Steps).To(Equal(4))
+	g.Expect(result.BaselineClusters).To(Equal([]string{"member1"}))
+	g.Expect(result.Changes).To(HaveLen(3))
+	g.Expect(result.Changes[1]).To(Equal(PlacementChange{
+		Timestamp:      hour(1),
+		ActiveClusters: []string{"member2"},
+		Added:          []string{"member2"},
+		Removed:        []string{"member1"},
+	}))
+	g.Expect(result.Changes[2].ActiveClusters).To(Equal([]string{"member1"}))
+	g.Expect(result.Migrations).To(Equal(2))
+
+	// Baseline is member1 for 3 hours at 60. Carbon aware is member1 for
+	// 1 hour at 60, member2 for 1 hour at 50 and member1 for 1 hour at 60.
+	g.Expect(result.Baseline).To(BeNumerically("~", 180))
+	g.Expect(result.CarbonAware).To(BeNumerically("~", 170))
+	g.Expect(result.Saved).To(BeNumerically("~", 10))
+}
+
+func TestSimulateMissingData(t *testing.T) {
+	g := NewWithT(t)
+
+	samples := []Sample{
+		{Timestamp: hour(0), Location: "DE", Value: 300},
+		{Timestamp: hour(1), Location: "ES", Value: 100},
+	}
+
+	// No cluster has data at the first step so there is no placement, as
+	// the controller leaves the placement unchanged.
+	result, err := Simulate(locations, 1, []string{"member2"}, samples)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Changes).To(HaveLen(2))
+	g.Expect(result.Changes[0].ActiveClusters).To(BeEmpty())
+	g.Expect(result.Changes[1].ActiveClusters).To(Equal([]string{"member2"}))
+
+	_, err = Simulate(locations, 1, []string{"member3"}, samples)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestLoadSamples(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+
+	csvFile := filepath.Join(dir, "samples.csv")
+	g.Expect(os.WriteFile(csvFile, []byte("timestamp,location,value\n2023-09-01T00:00:00Z,FR,60.5\n"), 0o600)).To(Succeed())
+	jsonFile := filepath.Join(dir, "samples.json")
+	g.Expect(os.WriteFile(jsonFile, []byte(`[{"timestamp":"2023-09-01T00:00:00Z","location":"FR","value":60.5}]`), 0o600)).To(Succeed())
+
+	expected := []Sample{{Timestamp: hour(0), Location: "FR", Value: 60.5}}
+	for _, path := range []string{csvFile, jsonFile} {
+		samples, err := LoadSamples(path)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(samples).To(Equal(expected))
+	}
+}